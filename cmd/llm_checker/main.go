@@ -0,0 +1,1069 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/revoxz/LLMtesting/internal/gguf"
+	"github.com/revoxz/LLMtesting/internal/sysinfo"
+)
+
+type SystemResources struct {
+	OS                 string `json:"os"`
+	Arch               string `json:"arch"`
+	CPUCores           int    `json:"cpu_cores"`
+	TotalRAM           int64  `json:"total_ram_gb"` // in GB
+	TotalRAMSource     string `json:"total_ram_source"`
+	TotalRAMKnown      bool   `json:"total_ram_known"` // false when detection failed and -total-ram-gb was not given
+	GPU                string `json:"gpu"`
+	GPUMemory          int64  `json:"gpu_memory_gb"`         // in GB
+	GPUMemoryKnown     bool   `json:"gpu_memory_known"`      // false when system_profiler (nvidia-smi or wmic on Windows) reported no usable VRAM
+	GPUMemoryIsUnified bool   `json:"gpu_memory_is_unified"` // GPUMemory is the GPU's share of unified RAM (Apple Silicon), not dedicated VRAM
+	GPUCores           int    `json:"gpu_cores"`             // Apple Silicon GPU cores, 0 if unknown
+	ChipGeneration     string `json:"chip_generation"`       // Apple Silicon chip, e.g. "M3 Max"; empty if unknown
+	HasMetalAPI        bool   `json:"has_metal_api"`
+}
+
+type ColimaInfo struct {
+	Installed bool   `json:"installed"`
+	Running   bool   `json:"running"`
+	CPUs      int    `json:"cpus"`
+	Memory    int64  `json:"memory_gb"` // in GB
+	Disk      int64  `json:"disk_gb"`   // in GB
+	Runtime   string `json:"runtime"`
+	Arch      string `json:"arch"`
+}
+
+type LLMModel struct {
+	Name         string
+	MinRAM       int64 // in GB
+	MinGPUMemory int64 // in GB (0 if CPU only)
+	RequiresGPU  bool
+	Sizing       string // how MinRAM was derived, for --explain; empty for the Q4 catalog figures
+}
+
+// Compatibility verdict for one model
+type ModelVerdict struct {
+	Name       string `json:"name"`
+	Compatible bool   `json:"compatible"`
+	Reason     string `json:"reason,omitempty"`  // why it can't run
+	Warning    string `json:"warning,omitempty"` // runs, but something couldn't be verified
+}
+
+// Everything the checker found, as printed by -json
+type CheckerResult struct {
+	System *SystemResources `json:"system"`
+	Colima *ColimaInfo      `json:"colima"`
+	Models []ModelVerdict   `json:"models"`
+}
+
+func main() {
+	explain := flag.Bool("explain", false, "Print the RAM math behind each model's compatibility verdict")
+	totalRAMGB := flag.Int64("total-ram-gb", 0, "Use this total RAM instead of detecting it (for restricted environments)")
+	var ggufFiles fileList
+	flag.Var(&ggufFiles, "gguf", "GGUF model file to inspect and check (repeatable)")
+	quantFamily := flag.String("quant-family", "Qwen 3", "Model family for the per-quantization fit table, e.g. \"Llama 3.1\" or \"DeepSeek R1\"")
+	jsonOutput := flag.Bool("json", false, "Print the system, Colima and per-model verdicts as one JSON document instead of the report")
+	flag.Parse()
+
+	if !*jsonOutput {
+		fmt.Print("=== LLM Compatibility Checker for Mac ===\n\n")
+	}
+
+	// Get system resources
+	resources, err := getSystemResources(*totalRAMGB)
+	if err != nil && *jsonOutput {
+		fmt.Fprintf(os.Stderr, "warning: could not detect system RAM: %v (pass -total-ram-gb N)\n", err)
+	} else if err != nil {
+		fmt.Printf("⚠️  Could not detect system RAM: %v\n", err)
+		fmt.Print("   Pass -total-ram-gb N to evaluate compatibility anyway.\n\n")
+	}
+
+	// Display system information
+	if !*jsonOutput {
+		displaySystemInfo(resources)
+	}
+
+	// Check Colima
+	colima := checkColima()
+	if !*jsonOutput {
+		displayColimaInfo(colima, resources)
+	}
+
+	// Define popular LLM models with their requirements
+	// RAM estimates are based on Q4/Q5 quantization (typical for Ollama)
+	// Formula: ~1.5-2GB per billion parameters for Q4, ~2-2.5GB for Q5
+	models := []LLMModel{
+		{"Llama 3.2 1B (Q4)", 2, 0, false, ""},
+		{"Llama 3.2 3B (Q4)", 4, 0, false, ""},
+		{"Llama 3.1 8B (Q4)", 6, 0, false, ""},
+		{"Llama 3.1 70B (Q4)", 40, 0, false, ""},
+		{"Llama 3.1 405B (Q4)", 220, 0, false, ""},
+		{"GPT-2 Small 124M (Q4)", 1, 0, false, ""},
+		{"GPT-2 Medium 355M (Q4)", 1, 0, false, ""},
+		{"GPT-2 Large 774M (Q4)", 2, 0, false, ""},
+		{"Mistral 7B (Q4)", 5, 0, false, ""},
+		{"Mixtral 8x7B (Q4)", 30, 0, false, ""},
+		{"Phi-3 Mini 3.8B (Q4)", 3, 0, false, ""},
+		{"Phi-3 Medium 14B (Q4)", 9, 0, false, ""},
+		{"Gemma 2B (Q4)", 2, 0, false, ""},
+		{"Gemma 7B (Q4)", 5, 0, false, ""},
+		{"CodeLlama 7B (Q4)", 5, 0, false, ""},
+		{"CodeLlama 13B (Q4)", 8, 0, false, ""},
+		{"CodeLlama 34B (Q4)", 20, 0, false, ""},
+		{"Qwen 2.5 0.5B (Q4)", 1, 0, false, ""},
+		{"Qwen 2.5 1.5B (Q4)", 2, 0, false, ""},
+		{"Qwen 2.5 7B (Q4)", 5, 0, false, ""},
+		{"Qwen 2.5 14B (Q4)", 9, 0, false, ""},
+		{"Qwen 3 0.6B (Q4)", 1, 0, false, ""},
+		{"Qwen 3 1.7B (Q4)", 2, 0, false, ""},
+		{"Qwen 3 3B (Q4)", 3, 0, false, ""},
+		{"Qwen 3 8B (Q4)", 6, 0, false, ""},
+		{"Qwen 3 14B (Q4)", 9, 0, false, ""},
+		{"Qwen 3 32B (Q4)", 20, 0, false, ""},
+		{"Qwen 3 70B (Q4)", 40, 0, false, ""},
+		{"Qwen 3 235B (Q4)", 130, 0, false, ""},
+		{"DeepSeek R1 1.5B (Q4)", 2, 0, false, ""},
+		{"DeepSeek R1 7B (Q4)", 5, 0, false, ""},
+		{"DeepSeek R1 8B (Q4)", 6, 0, false, ""},
+		{"DeepSeek R1 14B (Q4)", 9, 0, false, ""},
+		{"DeepSeek R1 32B (Q4)", 20, 0, false, ""},
+		{"DeepSeek R1 70B (Q4)", 40, 0, false, ""},
+		{"DeepSeek R1 671B (Q4)", 370, 0, false, ""},
+		{"DeepSeek Coder 1.3B (Q4)", 2, 0, false, ""},
+		{"DeepSeek Coder 6.7B (Q4)", 5, 0, false, ""},
+		{"DeepSeek Coder 33B (Q4)", 20, 0, false, ""},
+		{"Nomic Embed Text v1.5", 1, 0, false, ""},
+		{"Nomic Embed Text v1", 1, 0, false, ""},
+		{"Stable Diffusion XL", 10, 6, true, ""},
+		{"Stable Diffusion 1.5", 6, 4, true, ""},
+	}
+
+	// Local GGUF files are sized from their header instead of a table entry
+	if len(ggufFiles) > 0 && !*jsonOutput {
+		fmt.Print("\n=== GGUF Files ===\n\n")
+	}
+	for _, file := range ggufFiles {
+		info, err := gguf.ReadInfo(file)
+		if err != nil && *jsonOutput {
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", file, err)
+			continue
+		} else if err != nil {
+			fmt.Printf("  ✗ %s: %v\n", file, err)
+			continue
+		}
+		if !*jsonOutput {
+			fmt.Printf("  %s\n", file)
+			fmt.Printf("    Architecture: %s | Parameters: %s | Quantization: %s | Context: %d\n",
+				info.Architecture, gguf.FormatParameterCount(info.ParameterCount), info.Quantization, info.ContextLength)
+			fmt.Printf("    File size: %.1f GB | Estimated RAM: %d GB\n",
+				float64(info.FileSizeBytes)/(1024*1024*1024), gguf.RAMGB(info))
+		}
+		models = append(models, LLMModel{
+			Name:   fmt.Sprintf("%s %s (%s, GGUF)", info.Architecture, gguf.FormatParameterCount(info.ParameterCount), info.Quantization),
+			MinRAM: gguf.RAMGB(info),
+			Sizing: fmt.Sprintf("GGUF file x%.1f overhead (%s)", gguf.RuntimeOverheadFactor, info.Quantization),
+		})
+	}
+
+	if *jsonOutput {
+		result := CheckerResult{System: resources, Colima: colima}
+		for _, model := range models {
+			result.Models = append(result.Models, evaluateModel(resources, model))
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing JSON: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Without a RAM figure there is nothing to compare against, so just list the requirements
+	if !resources.TotalRAMKnown {
+		fmt.Print("\n=== Model Catalog ===\n\n")
+		displayModelCatalog(models)
+		return
+	}
+
+	// Check compatibility
+	fmt.Print("\n=== Model Compatibility Check ===\n\n")
+	checkModelCompatibility(resources, models, *explain, *quantFamily)
+}
+
+// displayModelCatalog prints every model's requirements without a verdict, for when RAM is unknown
+func displayModelCatalog(models []LLMModel) {
+	fmt.Println("⚠️  Compatibility could not be evaluated because total RAM is unknown.")
+	fmt.Print("   Re-run with -total-ram-gb N to get per-model verdicts.\n\n")
+	for _, model := range models {
+		requirements := fmt.Sprintf("RAM: %d GB", model.MinRAM)
+		if model.MinGPUMemory > 0 {
+			requirements += fmt.Sprintf(", GPU memory: %d GB", model.MinGPUMemory)
+		}
+		if model.RequiresGPU {
+			requirements += ", GPU required"
+		}
+		fmt.Printf("  %-30s %s\n", model.Name, requirements)
+	}
+}
+
+// fileList collects repeated -gguf flags
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func getSystemResources(totalRAMOverrideGB int64) (*SystemResources, error) {
+	resources := &SystemResources{
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		CPUCores: runtime.NumCPU(),
+	}
+
+	// Get total RAM; -total-ram-gb skips detection entirely. A failed detection is returned
+	// alongside the resources so the caller can still show everything that doesn't need RAM.
+	var ramErr error
+	if totalRAMOverrideGB > 0 {
+		resources.TotalRAM = totalRAMOverrideGB
+		resources.TotalRAMSource = "-total-ram-gb"
+		resources.TotalRAMKnown = true
+	} else if ramBytes, source, err := sysinfo.DetectTotalRAM(); err != nil {
+		ramErr = err
+	} else {
+		resources.TotalRAM = ramBytes / (1024 * 1024 * 1024) // Convert to GB
+		resources.TotalRAMSource = source
+		resources.TotalRAMKnown = true
+	}
+
+	// Get GPU information: system_profiler on macOS, nvidia-smi or the video controller list on Windows
+	if runtime.GOOS == "windows" {
+		resources.GPU, resources.GPUMemory, resources.GPUMemoryKnown = detectWindowsGPU()
+		return resources, ramErr
+	}
+	gpuCmd := exec.Command("system_profiler", "SPDisplaysDataType")
+	gpuOutput, err := gpuCmd.Output()
+	if err == nil {
+		gpuInfo := string(gpuOutput)
+		resources.GPU = extractGPUName(gpuInfo)
+		resources.GPUCores = extractGPUCores(gpuInfo)
+		resources.ChipGeneration = detectChipGeneration(resources.GPU)
+
+		// Check for Metal API support (all modern Macs have it)
+		if resources.GPU != "" && runtime.GOOS == "darwin" {
+			resources.HasMetalAPI = true
+		}
+		resources.GPUMemory, resources.GPUMemoryKnown = extractGPUMemory(gpuInfo, resources)
+		resources.GPUMemoryIsUnified = resources.GPUMemoryKnown && isUnifiedMemory(resources)
+	}
+
+	return resources, ramErr
+}
+
+// Relative GPU throughput per core of each Apple Silicon generation, M1 = 1. Later chips than the newest listed
+// get its figure.
+var chipGenerationSpeed = []float64{1.0, 1.15, 1.3, 1.5} // M1, M2, M3, M4
+
+// gpuTierAdvice maps the Apple GPU core count, weighted by the chip generation, to a model size class for MLX/Metal.
+// Tiers follow the chip lineup: base M-series (7-10), Pro (14-20), Max (30-40), Ultra (60+).
+func gpuTierAdvice(cores int, chip string) string {
+	effective := float64(cores) * chipSpeed(chip)
+	switch {
+	case effective >= 60:
+		return "excellent for 32B-70B models with MLX"
+	case effective >= 30:
+		return "excellent for 14B+ models with MLX"
+	case effective >= 14:
+		return "good for 7B-14B models with MLX"
+	case effective >= 9:
+		return "stick to smaller models (1B-8B) for responsive generation"
+	default:
+		return "stick to 1B-3B models; 7B-8B will run, but slowly"
+	}
+}
+
+// chipSpeed returns the per-core speed of chip's generation from chipGenerationSpeed, 1 if unknown
+func chipSpeed(chip string) float64 {
+	generation, err := strconv.Atoi(strings.TrimPrefix(strings.SplitN(chip, " ", 2)[0], "M"))
+	if err != nil || generation < 1 {
+		return 1
+	}
+	if generation > len(chipGenerationSpeed) {
+		generation = len(chipGenerationSpeed)
+	}
+	return chipGenerationSpeed[generation-1]
+}
+
+// detectChipGeneration names the Apple Silicon chip, e.g. "M3 Max", from sysctl machdep.cpu.brand_string,
+// falling back to the GPU chipset name; empty on other hardware
+func detectChipGeneration(gpuName string) string {
+	if output, err := exec.Command("sysctl", "-n", "machdep.cpu.brand_string").Output(); err == nil {
+		if chip := parseAppleChip(string(output)); chip != "" {
+			return chip
+		}
+	}
+	return parseAppleChip(gpuName)
+}
+
+// parseAppleChip extracts the chip from a name like "Apple M2 Pro": the generation and the Pro/Max/Ultra tier, if any
+func parseAppleChip(name string) string {
+	fields := strings.Fields(name)
+	for i, field := range fields {
+		if len(field) < 2 || field[0] != 'M' {
+			continue
+		}
+		if _, err := strconv.Atoi(field[1:]); err != nil {
+			continue
+		}
+		if i+1 < len(fields) {
+			switch fields[i+1] {
+			case "Pro", "Max", "Ultra":
+				return field + " " + fields[i+1]
+			}
+		}
+		return field
+	}
+	return ""
+}
+
+func extractGPUName(gpuInfo string) string {
+	lines := strings.Split(gpuInfo, "\n")
+	for _, line := range lines {
+		if strings.Contains(line, "Chipset Model:") {
+			parts := strings.Split(line, ":")
+			if len(parts) > 1 {
+				return strings.TrimSpace(parts[1])
+			}
+		}
+	}
+	return "Unknown"
+}
+
+// extractGPUCores reads "Total Number of Cores: 38" from system_profiler output
+func extractGPUCores(gpuInfo string) int {
+	for _, line := range strings.Split(gpuInfo, "\n") {
+		if strings.Contains(line, "Total Number of Cores:") {
+			parts := strings.Split(line, ":")
+			if cores, err := strconv.Atoi(strings.TrimSpace(parts[len(parts)-1])); err == nil {
+				return cores
+			}
+		}
+	}
+	return 0
+}
+
+// Win32_VideoController.AdapterRAM is a 32-bit byte count, so any card with 4 GB or more reads as this or close to it
+const adapterRAMCap = 0xFFF00000
+
+// detectWindowsGPU returns the name and VRAM in GB of the GPU with the most memory, e.g. an RTX card next to
+// integrated graphics. nvidia-smi reports real VRAM; otherwise AdapterRAM from wmic (or PowerShell) is used,
+// and a value at its 4 GB cap leaves the VRAM unknown rather than understating it.
+func detectWindowsGPU() (string, int64, bool) {
+	query := []string{"--query-gpu=name,memory.total", "--format=csv,noheader,nounits"}
+	if output, err := exec.Command("nvidia-smi", query...).Output(); err == nil {
+		name, mib := "", 0.0
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			fields := strings.Split(line, ",")
+			if len(fields) != 2 {
+				continue
+			}
+			if value, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64); err == nil && value > mib {
+				name, mib = strings.TrimSpace(fields[0]), value
+			}
+		}
+		if name != "" {
+			return name, int64(math.Round(mib / 1024)), true
+		}
+	}
+
+	output, err := exec.Command("wmic", "path", "win32_VideoController", "get", "Name,AdapterRAM", "/value").Output()
+	if err != nil {
+		ps := "Get-CimInstance Win32_VideoController | Format-List AdapterRAM,Name"
+		if output, err = exec.Command("powershell", "-NoProfile", "-Command", ps).Output(); err != nil {
+			return "Unknown", 0, false
+		}
+	}
+	name, ramBytes := "Unknown", int64(-1)
+	for _, record := range sysinfo.ParseWindowsRecords(string(output)) {
+		bytes, err := strconv.ParseInt(record["AdapterRAM"], 10, 64)
+		if err != nil {
+			bytes = 0
+		}
+		if record["Name"] != "" && bytes > ramBytes {
+			name, ramBytes = record["Name"], bytes
+		}
+	}
+	if ramBytes <= 0 || ramBytes >= adapterRAMCap {
+		return name, 0, false
+	}
+	return name, int64(math.Round(float64(ramBytes) / (1024 * 1024 * 1024))), true
+}
+
+// hasGPUAcceleration reports a GPU that Ollama can offload to: Metal on a Mac, or a dedicated card on Windows
+func hasGPUAcceleration(resources *SystemResources) bool {
+	if resources.HasMetalAPI {
+		return true
+	}
+	return resources.OS == "windows" && resources.GPU != "" && resources.GPU != "Unknown"
+}
+
+// Share of unified memory the GPU (and so a model) can use safely on Apple Silicon
+const unifiedMemoryGPUFraction = 0.7
+
+// isUnifiedMemory reports an Apple Silicon GPU, which shares system RAM instead of having VRAM
+func isUnifiedMemory(resources *SystemResources) bool {
+	return resources.Arch == "arm64" && resources.HasMetalAPI
+}
+
+// extractGPUMemory returns the GPU memory in GB and whether it is known. Apple Silicon reports no VRAM
+// line, so its budget is the GPU-usable share of unified RAM (unknown if RAM is); discrete GPUs report
+// VRAM, and parsing can fail on unusual output.
+func extractGPUMemory(gpuInfo string, resources *SystemResources) (int64, bool) {
+	if isUnifiedMemory(resources) {
+		if !resources.TotalRAMKnown {
+			return 0, false
+		}
+		return int64(float64(resources.TotalRAM) * unifiedMemoryGPUFraction), true
+	}
+
+	lines := strings.Split(gpuInfo, "\n")
+	for _, line := range lines {
+		if strings.Contains(line, "VRAM") || strings.Contains(line, "Metal Support") {
+			// Try to extract memory size, e.g. "VRAM (Total): 4 GB" or "VRAM (Dynamic, Max): 1536 MB"
+			parts := strings.Fields(line)
+			for i, part := range parts {
+				if i == 0 || (!strings.Contains(part, "GB") && !strings.Contains(part, "MB")) {
+					continue
+				}
+				memStr := strings.TrimSpace(parts[i-1])
+				if mem, err := strconv.ParseFloat(memStr, 64); err == nil {
+					if strings.Contains(part, "MB") {
+						mem /= 1024
+					}
+					return int64(mem), true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+func displaySystemInfo(resources *SystemResources) {
+	fmt.Println("System Information:")
+	fmt.Printf("  OS: %s\n", resources.OS)
+	fmt.Printf("  Architecture: %s\n", resources.Arch)
+	fmt.Printf("  CPU Cores: %d\n", resources.CPUCores)
+	if resources.TotalRAMKnown {
+		fmt.Printf("  Total RAM: %d GB (%s)\n", resources.TotalRAM, resources.TotalRAMSource)
+	} else {
+		fmt.Printf("  Total RAM: Unknown (detection failed)\n")
+	}
+	fmt.Printf("  GPU: %s\n", resources.GPU)
+	if resources.ChipGeneration != "" {
+		fmt.Printf("  Chip: Apple %s\n", resources.ChipGeneration)
+	}
+	if resources.GPUCores > 0 {
+		fmt.Printf("  GPU Cores: %d\n", resources.GPUCores)
+	}
+	if resources.GPUMemoryIsUnified {
+		fmt.Printf("  GPU Memory: %d GB (%.0f%% of %d GB unified memory)\n",
+			resources.GPUMemory, unifiedMemoryGPUFraction*100, resources.TotalRAM)
+	} else if resources.GPUMemoryKnown {
+		fmt.Printf("  GPU Memory: %d GB\n", resources.GPUMemory)
+	} else if resources.OS == "windows" {
+		fmt.Printf("  GPU Memory: Unknown (Windows reports at most 4 GB without nvidia-smi)\n")
+	} else if resources.Arch == "arm64" {
+		fmt.Printf("  GPU Memory: Unified memory (shared with RAM)\n")
+	} else {
+		fmt.Printf("  GPU Memory: Unknown (could not read VRAM from system_profiler)\n")
+	}
+	fmt.Printf("  Metal API Support: %v\n", resources.HasMetalAPI)
+}
+
+func checkColima() *ColimaInfo {
+	info := &ColimaInfo{}
+
+	// Check if colima is installed
+	_, err := exec.LookPath("colima")
+	if err != nil {
+		info.Installed = false
+		return info
+	}
+	info.Installed = true
+
+	// Check if colima is running
+	statusCmd := exec.Command("colima", "status")
+	statusOutput, err := statusCmd.CombinedOutput()
+	if err != nil || !strings.Contains(string(statusOutput), "running") {
+		info.Running = false
+		return info
+	}
+	info.Running = true
+
+	// Try to get configuration from JSON output first
+	listCmd := exec.Command("colima", "list", "--json")
+	listOutput, err := listCmd.Output()
+	if err == nil {
+		// Try to parse as single JSON object (not array)
+		var instance map[string]interface{}
+		if err := json.Unmarshal(listOutput, &instance); err == nil {
+			// Parse CPUs
+			if cpus, ok := instance["cpus"].(float64); ok {
+				info.CPUs = int(cpus)
+			}
+
+			// Parse Memory (in bytes, convert to GB)
+			if memory, ok := instance["memory"].(float64); ok {
+				info.Memory = int64(memory) / (1024 * 1024 * 1024)
+			}
+
+			// Parse Disk (in bytes, convert to GB)
+			if disk, ok := instance["disk"].(float64); ok {
+				info.Disk = int64(disk) / (1024 * 1024 * 1024)
+			}
+
+			// Parse Runtime
+			if runtimeStr, ok := instance["runtime"].(string); ok {
+				info.Runtime = runtimeStr
+			}
+
+			// Parse Arch
+			if arch, ok := instance["arch"].(string); ok {
+				info.Arch = arch
+			}
+
+			return info
+		}
+	}
+
+	// Fallback: Try using colima status for more details
+	statusCmd2 := exec.Command("colima", "status", "--verbose")
+	statusOutput2, err := statusCmd2.Output()
+	if err == nil {
+		output := string(statusOutput2)
+
+		// Parse CPU from verbose status
+		if strings.Contains(output, "cpu:") {
+			lines := strings.Split(output, "\n")
+			for _, line := range lines {
+				if strings.Contains(line, "cpu:") {
+					parts := strings.Fields(line)
+					if len(parts) >= 2 {
+						if cpu, err := strconv.Atoi(parts[1]); err == nil {
+							info.CPUs = cpu
+						}
+					}
+				}
+			}
+		}
+
+		// Parse Memory from verbose status
+		if strings.Contains(output, "memory:") {
+			lines := strings.Split(output, "\n")
+			for _, line := range lines {
+				if strings.Contains(line, "memory:") {
+					parts := strings.Fields(line)
+					if len(parts) >= 2 {
+						memStr := strings.TrimSuffix(parts[1], "GiB")
+						memStr = strings.TrimSuffix(memStr, "GB")
+						if mem, err := strconv.ParseInt(memStr, 10, 64); err == nil {
+							info.Memory = mem
+						}
+					}
+				}
+			}
+		}
+
+		// Parse Disk from verbose status
+		if strings.Contains(output, "disk:") {
+			lines := strings.Split(output, "\n")
+			for _, line := range lines {
+				if strings.Contains(line, "disk:") {
+					parts := strings.Fields(line)
+					if len(parts) >= 2 {
+						diskStr := strings.TrimSuffix(parts[1], "GiB")
+						diskStr = strings.TrimSuffix(diskStr, "GB")
+						if disk, err := strconv.ParseInt(diskStr, 10, 64); err == nil {
+							info.Disk = disk
+						}
+					}
+				}
+			}
+		}
+
+		// Parse Runtime from verbose status
+		if strings.Contains(output, "runtime:") {
+			lines := strings.Split(output, "\n")
+			for _, line := range lines {
+				if strings.Contains(line, "runtime:") {
+					parts := strings.Fields(line)
+					if len(parts) >= 2 {
+						info.Runtime = parts[1]
+					}
+				}
+			}
+		}
+
+		// Parse Arch from verbose status
+		if strings.Contains(output, "arch:") {
+			lines := strings.Split(output, "\n")
+			for _, line := range lines {
+				if strings.Contains(line, "arch:") {
+					parts := strings.Fields(line)
+					if len(parts) >= 2 {
+						info.Arch = parts[1]
+					}
+				}
+			}
+		}
+	}
+
+	return info
+}
+
+func displayColimaInfo(colima *ColimaInfo, resources *SystemResources) {
+	fmt.Println("\n=== Colima (Container Runtime) Check ===")
+
+	if !colima.Installed {
+		fmt.Println("Status: Not installed")
+		fmt.Println("ℹ️  Colima is a container runtime for macOS (alternative to Docker Desktop)")
+		fmt.Println("   Install: brew install colima")
+		return
+	}
+
+	fmt.Println("Status: Installed ✓")
+
+	if !colima.Running {
+		fmt.Println("Running: No")
+		fmt.Println("ℹ️  Start Colima: colima start")
+		return
+	}
+
+	fmt.Println("Running: Yes ✓")
+	fmt.Printf("\nColima Configuration:\n")
+	fmt.Printf("  CPUs: %d (of %d system cores)\n", colima.CPUs, resources.CPUCores)
+	if resources.TotalRAMKnown {
+		fmt.Printf("  Memory: %d GB (of %d GB system RAM)\n", colima.Memory, resources.TotalRAM)
+	} else {
+		fmt.Printf("  Memory: %d GB\n", colima.Memory)
+	}
+	fmt.Printf("  Disk: %d GB\n", colima.Disk)
+	fmt.Printf("  Runtime: %s\n", colima.Runtime)
+	fmt.Printf("  Architecture: %s\n", colima.Arch)
+
+	// Recommendations
+	fmt.Println("\n=== Colima Recommendations ===")
+
+	recommendedCPU := resources.CPUCores / 2
+	if recommendedCPU < 2 {
+		recommendedCPU = 2
+	}
+	if recommendedCPU > 8 {
+		recommendedCPU = 8
+	}
+
+	recommendedRAM := resources.TotalRAM / 2
+	if recommendedRAM < 4 {
+		recommendedRAM = 4
+	}
+	if recommendedRAM > 16 {
+		recommendedRAM = 16
+	}
+
+	needsReconfiguration := false
+
+	if colima.CPUs < recommendedCPU {
+		fmt.Printf("⚠️  CPU: Consider increasing to %d cores for better performance\n", recommendedCPU)
+		needsReconfiguration = true
+	} else {
+		fmt.Printf("✓ CPU: %d cores is good\n", colima.CPUs)
+	}
+
+	// RAM recommendations are a share of system RAM, so they need a RAM figure
+	if !resources.TotalRAMKnown {
+		fmt.Printf("• RAM: %d GB (system RAM unknown; pass -total-ram-gb N for a recommendation)\n", colima.Memory)
+	} else if colima.Memory < recommendedRAM {
+		fmt.Printf("⚠️  RAM: Consider increasing to %d GB for better performance\n", recommendedRAM)
+		needsReconfiguration = true
+	} else {
+		fmt.Printf("✓ RAM: %d GB is good\n", colima.Memory)
+	}
+
+	// LLM-specific recommendations
+	fmt.Println("\n=== Running LLMs in Containers (Ollama in Colima) ===")
+
+	maxLLMRAM := colima.Memory - 2 // Reserve 2GB for system
+	if maxLLMRAM < 0 {
+		maxLLMRAM = 0
+	}
+
+	fmt.Printf("Available RAM for LLMs in containers: ~%d GB\n", maxLLMRAM)
+
+	if maxLLMRAM < 4 {
+		fmt.Println("⚠️  WARNING: Not enough RAM for most LLMs in containers")
+		fmt.Println("   Recommendation: Increase Colima RAM to at least 8 GB")
+		fmt.Println("   Or run Ollama directly on your Mac (not in container)")
+	} else if maxLLMRAM < 8 {
+		fmt.Println("✓ You can run small models (1B-3B) in containers")
+		fmt.Println("  Recommended: Llama 3.2 3B, Qwen 3 3B")
+	} else if maxLLMRAM < 16 {
+		fmt.Println("✓ You can run medium models (3B-8B) in containers")
+		fmt.Println("  Recommended: Llama 3.1 8B, Qwen 2.5 7B")
+	} else {
+		fmt.Println("✓ You can run large models (8B-14B+) in containers")
+		fmt.Println("  Recommended: Llama 3.1 8B, Phi-3 Medium 14B, Mixtral 8x7B")
+	}
+
+	if needsReconfiguration {
+		fmt.Println("\n💡 To reconfigure Colima:")
+		fmt.Printf("   colima stop\n")
+		if resources.TotalRAMKnown {
+			fmt.Printf("   colima start --cpu %d --memory %d\n", recommendedCPU, recommendedRAM)
+		} else {
+			fmt.Printf("   colima start --cpu %d\n", recommendedCPU)
+		}
+	}
+
+	// Detailed comparison and recommendations
+	fmt.Println("\n=== Bare Metal vs Colima Comparison ===")
+	fmt.Println("\n📊 Performance Comparison:")
+	fmt.Println("┌─────────────────────┬────────────────────┬─────────────────────┐")
+	fmt.Println("│ Aspect              │ Bare Metal (macOS) │ Colima (Container)  │")
+	fmt.Println("├─────────────────────┼────────────────────┼─────────────────────┤")
+	fmt.Println("│ Speed               │ ✓✓✓ Fastest        │ ✓✓ Good             │")
+	fmt.Println("│ RAM Overhead        │ ✓✓✓ Minimal        │ ✓ +2-4GB overhead   │")
+	fmt.Println("│ Metal API           │ ✓✓✓ Full access    │ ✗ Limited/None      │")
+	fmt.Println("│ Setup               │ ✓✓✓ Simple         │ ✓✓ Moderate         │")
+	fmt.Println("│ Isolation           │ ✗ None             │ ✓✓✓ Full isolation  │")
+	fmt.Println("│ Portability         │ ✓ macOS only       │ ✓✓✓ Portable        │")
+	fmt.Println("└─────────────────────┴────────────────────┴─────────────────────┘")
+
+	fmt.Println("\n📝 Recommendations:")
+	fmt.Println("\n✅ Use Bare Metal (Direct macOS) when:")
+	fmt.Println("   • You want maximum performance (especially on Apple Silicon)")
+	fmt.Println("   • You need full Metal API GPU acceleration")
+	fmt.Println("   • You have limited RAM and want minimal overhead")
+	fmt.Println("   • You're doing interactive development/testing")
+	fmt.Println("\n   Setup: brew install ollama && ollama serve")
+
+	fmt.Println("\n✅ Use Colima (Container) when:")
+	fmt.Println("   • You need isolated, reproducible environments")
+	fmt.Println("   • You're deploying to production (Docker compatibility)")
+	fmt.Println("   • You want to easily snapshot/restore configurations")
+	fmt.Println("   • You're running multiple different LLM setups")
+	fmt.Println("\n   Setup: brew install colima && colima start --cpu 6 --memory 12")
+
+	if colima.Running {
+		fmt.Printf("\n💡 Your Current Colima Configuration:")
+		fmt.Printf("\n   colima start --cpu %d --memory %d --disk %d --runtime %s --arch %s\n",
+			colima.CPUs, colima.Memory, colima.Disk, colima.Runtime, colima.Arch)
+	}
+
+	fmt.Println("\n💡 Recommended Colima Configuration for LLMs:")
+	optimalCPU := resources.CPUCores / 2
+	if optimalCPU < 4 {
+		optimalCPU = 4
+	}
+	if optimalCPU > 8 {
+		optimalCPU = 8
+	}
+	optimalRAM := resources.TotalRAM / 2
+	if optimalRAM < 12 {
+		optimalRAM = 12
+	}
+	if optimalRAM > 32 {
+		optimalRAM = 32
+	}
+	if resources.TotalRAMKnown {
+		fmt.Printf("   colima start --cpu %d --memory %d --disk 100 --runtime docker --vm-type vz --mount-type virtiofs\n", optimalCPU, optimalRAM)
+	} else {
+		fmt.Printf("   colima start --cpu %d --disk 100 --runtime docker --vm-type vz --mount-type virtiofs\n", optimalCPU)
+	}
+	fmt.Println("\n   Why these settings?")
+	fmt.Printf("   • CPU: %d cores (50%% of system) - good balance\n", optimalCPU)
+	if resources.TotalRAMKnown {
+		fmt.Printf("   • RAM: %d GB (50%% of system) - enough for medium/large models\n", optimalRAM)
+	} else {
+		fmt.Println("   • RAM: system RAM unknown; pass -total-ram-gb N to size --memory")
+	}
+	fmt.Println("   • Disk: 100 GB - sufficient for multiple models")
+	fmt.Println("   • VM type vz - better performance on Apple Silicon")
+	fmt.Println("   • Mount virtiofs - faster file sharing")
+
+	fmt.Println("\n🎯 Bottom Line:")
+	switch {
+	case resources.Arch == "arm64" && resources.HasMetalAPI:
+		fmt.Println("   For Apple Silicon: Bare Metal is 20-30% faster due to Metal API")
+	case resources.OS == "darwin":
+		fmt.Println("   For Intel Macs: Bare Metal is 10-15% faster, less overhead")
+	case hasGPUAcceleration(resources):
+		fmt.Println("   With a dedicated GPU: run Ollama on bare metal, where it can offload to the card; a VM runs on the CPU only")
+	default:
+		fmt.Println("   CPU-only: Bare Metal is 10-15% faster, less overhead")
+	}
+	if resources.TotalRAMKnown {
+		available, _ := availableModelMemory(resources)
+		fmt.Printf("   Current system: %d GB RAM → Bare Metal: ~%d GB for LLMs | Colima (%dGB): ~%d GB for LLMs\n",
+			resources.TotalRAM,
+			available,
+			colima.Memory,
+			colima.Memory-2)
+	} else {
+		fmt.Printf("   Current system: RAM unknown | Colima (%dGB): ~%d GB for LLMs\n", colima.Memory, colima.Memory-2)
+	}
+}
+
+// availableModelMemory returns the RAM models may use and how it was derived, using the smart benchmark's
+// default OS reserve tiers so both tools agree on what fits
+func availableModelMemory(resources *SystemResources) (int64, string) {
+	reserveGB, tier := sysinfo.OSReserveGB(resources.TotalRAM, nil)
+	return resources.TotalRAM - reserveGB, fmt.Sprintf("%d GB total - %d GB OS reserve (%.0f%% tier)",
+		resources.TotalRAM, reserveGB, tier.ReservePercent)
+}
+
+// RAM relative to Q4 for the other common quantization levels
+var quantRAMFactors = []struct {
+	Level  string
+	Factor float64
+}{
+	{"Q4", 1.0},
+	{"Q5", 1.2},
+	{"Q8", 1.5},
+}
+
+// displayQuantFitTable shows, per quantization level, the largest Q4-table model of family that fits in RAM
+func displayQuantFitTable(resources *SystemResources, models []LLMModel, family string) {
+	var variants []LLMModel
+	for _, model := range models {
+		if strings.HasPrefix(model.Name, family+" ") && strings.HasSuffix(model.Name, "(Q4)") {
+			variants = append(variants, model)
+		}
+	}
+	if len(variants) == 0 {
+		fmt.Printf("No Q4 models named %q in the table; pass -quant-family with a name like \"Llama 3.1\" or \"Qwen 2.5\".\n", family)
+		return
+	}
+
+	available, note := availableModelMemory(resources)
+	fmt.Printf("Largest %s model that fits in %d GB (%s):\n", family, available, note)
+	for _, quant := range quantRAMFactors {
+		best := ""
+		var bestRAM int64
+		for _, v := range variants {
+			ram := int64(math.Ceil(float64(v.MinRAM) * quant.Factor))
+			if ram <= available && ram > bestRAM {
+				best, bestRAM = strings.TrimSuffix(v.Name, " (Q4)"), ram
+			}
+		}
+		if best == "" {
+			fmt.Printf("  %s: none fits\n", quant.Level)
+		} else {
+			fmt.Printf("  %s: %-22s ~%d GB\n", quant.Level, best, bestRAM)
+		}
+	}
+}
+
+// evaluateModel decides whether one model can run on this machine, and why not
+func evaluateModel(resources *SystemResources, model LLMModel) ModelVerdict {
+	verdict := ModelVerdict{Name: model.Name, Compatible: true}
+	if !resources.TotalRAMKnown {
+		verdict.Compatible = false
+		verdict.Reason = "Not evaluated: total RAM unknown (pass -total-ram-gb N)"
+		return verdict
+	}
+
+	availableMemory, _ := availableModelMemory(resources)
+
+	// Check RAM requirement
+	if model.MinRAM > availableMemory {
+		verdict.Compatible = false
+		verdict.Reason = fmt.Sprintf("Insufficient RAM (need %d GB, have %d GB available)", model.MinRAM, availableMemory)
+	}
+
+	// Check GPU requirement for models that need dedicated GPU
+	if model.RequiresGPU && !hasGPUAcceleration(resources) {
+		verdict.Compatible = false
+		verdict.Reason = "Requires GPU acceleration (Metal API not available)"
+		if resources.OS == "windows" {
+			verdict.Reason = "Requires GPU acceleration (no dedicated GPU found)"
+		}
+	}
+
+	// Check GPU memory: dedicated VRAM on Intel Macs, the unified budget on Apple Silicon.
+	// Unknown VRAM only warns: a parse failure must not rule out a real discrete GPU.
+	if model.MinGPUMemory > 0 {
+		if !resources.GPUMemoryKnown {
+			verdict.Warning = fmt.Sprintf("GPU memory unknown, check that your GPU has at least %d GB", model.MinGPUMemory)
+		} else if resources.GPUMemory < model.MinGPUMemory {
+			verdict.Compatible = false
+			verdict.Reason = fmt.Sprintf("Insufficient GPU memory (need %d GB, have %d GB)", model.MinGPUMemory, resources.GPUMemory)
+		}
+	}
+	return verdict
+}
+
+func checkModelCompatibility(resources *SystemResources, models []LLMModel, explain bool, quantFamily string) {
+	compatible := []string{}
+	incompatible := []string{}
+	explanations := []string{}
+
+	for _, model := range models {
+		verdict := evaluateModel(resources, model)
+		canRun, reason, warning := verdict.Compatible, verdict.Reason, verdict.Warning
+		availableMemory, availableNote := availableModelMemory(resources)
+
+		if explain {
+			verdict := "compatible"
+			if !canRun {
+				verdict = "incompatible: " + reason
+			}
+			sizing := model.Sizing
+			if sizing == "" {
+				sizing = "Q4, x1.0"
+			}
+			line := fmt.Sprintf("  %-30s needs %d GB (%s) vs %d GB available (%s) → %s",
+				model.Name, model.MinRAM, sizing, availableMemory, availableNote, verdict)
+			if model.MinGPUMemory > 0 {
+				if resources.GPUMemoryKnown {
+					line += fmt.Sprintf(" [GPU memory: needs %d GB, has %d GB]", model.MinGPUMemory, resources.GPUMemory)
+				} else {
+					line += fmt.Sprintf(" [GPU memory: needs %d GB, has unknown]", model.MinGPUMemory)
+				}
+			}
+			explanations = append(explanations, line)
+		}
+
+		if canRun {
+			status := "✓"
+			requirements := ""
+
+			// Build requirements string
+			if model.MinRAM > 0 {
+				requirements = fmt.Sprintf("RAM: %d GB", model.MinRAM)
+			}
+			if model.MinGPUMemory > 0 {
+				if requirements != "" {
+					requirements += ", "
+				}
+				requirements += fmt.Sprintf("GPU Memory: %d GB", model.MinGPUMemory)
+			}
+			if model.RequiresGPU {
+				if requirements != "" {
+					requirements += ", "
+				}
+				requirements += "GPU required"
+			}
+
+			if resources.Arch == "arm64" && resources.HasMetalAPI {
+				status += " (Metal optimized)"
+			}
+
+			entry := fmt.Sprintf("  %s %-30s [%s]", status, model.Name, requirements)
+			if warning != "" {
+				entry += " ⚠️  " + warning
+			}
+			compatible = append(compatible, entry)
+		} else {
+			incompatible = append(incompatible, fmt.Sprintf("  ✗ %s - %s", model.Name, reason))
+		}
+	}
+
+	// Display results
+	fmt.Println("Compatible Models:")
+	if len(compatible) > 0 {
+		for _, model := range compatible {
+			fmt.Println(model)
+		}
+	} else {
+		fmt.Println("  None")
+	}
+
+	fmt.Println("\nIncompatible Models:")
+	if len(incompatible) > 0 {
+		for _, model := range incompatible {
+			fmt.Println(model)
+		}
+	} else {
+		fmt.Println("  None")
+	}
+
+	if explain {
+		fmt.Println("\nRAM Math:")
+		for _, line := range explanations {
+			fmt.Println(line)
+		}
+	}
+
+	// Recommendations
+	fmt.Println("\n=== Recommendations ===")
+
+	// Architecture-specific recommendations
+	if resources.Arch == "arm64" && resources.HasMetalAPI {
+		fmt.Println("✓ Your Mac has Apple Silicon with Metal support - excellent for running LLMs!")
+		fmt.Println("✓ Consider using llama.cpp, Ollama, or MLX for optimized performance")
+		if resources.GPUCores > 0 {
+			chip := ""
+			if resources.ChipGeneration != "" {
+				chip = resources.ChipGeneration + " with "
+			}
+			fmt.Printf("✓ %s%d-core GPU — %s\n", chip, resources.GPUCores, gpuTierAdvice(resources.GPUCores, resources.ChipGeneration))
+		}
+	} else if resources.OS == "windows" && hasGPUAcceleration(resources) {
+		fmt.Printf("✓ Your PC has a dedicated GPU (%s) - Ollama offloads model layers to it (CUDA on NVIDIA, ROCm on AMD)\n", resources.GPU)
+		if resources.GPUMemoryKnown {
+			fmt.Printf("✓ Models up to ~%d GB run entirely in VRAM; larger ones are split with system RAM and run slower\n", resources.GPUMemory)
+		} else {
+			fmt.Println("✓ Models that fit in the card's VRAM run entirely on the GPU; larger ones are split with system RAM and run slower")
+		}
+	} else if resources.OS == "darwin" {
+		fmt.Println("• Your Mac has Intel architecture - LLMs will run slower than on Apple Silicon")
+		fmt.Println("• Consider using llama.cpp or Ollama for CPU inference")
+	} else {
+		fmt.Println("• No GPU acceleration detected - LLMs will run on the CPU")
+		fmt.Println("• Consider using llama.cpp or Ollama for CPU inference")
+	}
+
+	// RAM-specific recommendations
+	fmt.Println()
+	if resources.TotalRAM >= 64 {
+		fmt.Println("✓ You have plenty of RAM for large models (32B-70B with Q4)")
+		fmt.Println("  Suggested: Llama 3.1 70B, Qwen 3 70B, Mixtral 8x7B")
+	} else if resources.TotalRAM >= 32 {
+		fmt.Println("✓ You have good RAM for medium-sized models (7B-32B with Q4)")
+		fmt.Println("  Suggested: Llama 3.1 8B, DeepSeek R1 32B, CodeLlama 34B")
+	} else if resources.TotalRAM >= 16 {
+		fmt.Println("✓ You have sufficient RAM for small-medium models (1B-14B with Q4)")
+		fmt.Println("  Suggested: Qwen 2.5 7B, Phi-3 Medium, DeepSeek Coder 6.7B")
+	} else {
+		fmt.Println("• Your RAM is limited - stick to smaller models (0.5B-3B)")
+		fmt.Println("  Suggested: Llama 3.2 3B, Qwen 3 3B, Phi-3 Mini")
+	}
+
+	// Quantization recommendations
+	fmt.Println("\n=== About Quantization ===")
+	fmt.Println("All RAM estimates above assume Q4 quantization (most common).")
+	displayQuantFitTable(resources, models, quantFamily)
+	fmt.Println()
+	fmt.Println("• Q4: Best balance of quality and size (~0.5-0.6GB per billion params)")
+	fmt.Println("• Q5: Better quality, 20% more RAM")
+	fmt.Println("• Q8: Near-perfect quality, 50% more RAM")
+	fmt.Println("• Q2: Very small, noticeable quality loss")
+	fmt.Println("\nTo use specific quantization in Ollama:")
+	fmt.Println("  ollama pull llama3.1:8b-instruct-q4_0   # Q4 (recommended)")
+	fmt.Println("  ollama pull llama3.1:8b-instruct-q5_K_M # Q5 (better quality)")
+	fmt.Println("  ollama pull llama3.1:8b-instruct-q8_0   # Q8 (best quality)")
+	fmt.Println("\nNote: Most models have '-instruct' variant for chat/instruction following")
+}
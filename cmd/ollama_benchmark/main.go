@@ -0,0 +1,460 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/revoxz/LLMtesting/internal/ollama"
+)
+
+// Benchmark test case
+type TestCase struct {
+	Name           string
+	Prompt         string
+	Category       string
+	ExpectedLen    int      // typical answer length in tokens; 0 skips the length check
+	ExpectKeywords []string // words a correct answer mentions (case-insensitive)
+	ExpectRegex    string   // pattern a correct answer matches; empty skips the check
+}
+
+// Benchmark result
+type BenchmarkResult struct {
+	ModelName        string
+	TestName         string
+	Category         string
+	TokensPerSecond  float64
+	TimeToFirstToken float64 // milliseconds; measured wall-clock with -stream, else load + prompt eval
+	Streamed         bool    // TimeToFirstToken was measured from the first streamed chunk
+	LoadTimeMs       float64 // server-reported load_duration
+	PromptEvalTimeMs float64 // server-reported prompt_eval_duration
+	TotalTokens      int
+	PromptTokens     int
+	TotalTimeMs      float64
+	Response         string
+	QualityScore     float64 // 0-1 from keyword hits, regex match and length; -1 if the test has no checks
+	Success          bool
+	Error            string
+}
+
+// Models benchmarked when neither -models nor -all is given
+var defaultModels = []string{
+	"llama3.2:1b",
+	"llama3.2:3b",
+	"gemma2:2b",
+	"qwen2.5:0.5b",
+}
+
+// Model comparison summary
+type ModelComparison struct {
+	ModelName       string
+	AvgTokensPerSec float64
+	AvgTotalTimeMs  float64
+	AvgQuality      float64 // mean QualityScore of the scored tests; -1 if none were scored
+	TestResults     []BenchmarkResult
+}
+
+func main() {
+	hostFlag := flag.String("host", "", "Ollama endpoint (default $OLLAMA_HOST or "+ollama.DefaultHost+")")
+	stream := flag.Bool("stream", false, "Stream responses and measure the real time to the first token")
+	temperature := flag.Float64("temperature", 0, "Sampling temperature (0 = greedy, so repeated runs generate the same text)")
+	seed := flag.Int("seed", 42, "Random seed for sampling")
+	numCtx := flag.Int("num-ctx", 0, "Context window in tokens (0 = the model's default)")
+	authToken := flag.String("auth-token", "", "Send \"Authorization: Bearer TOKEN\" with every Ollama request, for endpoints behind an auth proxy")
+	flag.Var(ollama.HeaderFlag{}, "header", "Add a header to every Ollama request, as Name=Value (repeatable)")
+	flag.DurationVar(&ollama.RequestClient.Timeout, "timeout", ollama.DefaultRequestTimeout, "Give up on a generate request after this long, e.g. 90s or 5m (0 = never)")
+	modelList := flag.String("models", "", "Comma-separated models to benchmark, e.g. llama3.1:8b,qwen2.5:7b (default: "+strings.Join(defaultModels, ",")+")")
+	allInstalled := flag.Bool("all", false, "Benchmark every model installed in Ollama")
+	flag.Parse()
+	ollama.SetAuthToken(*authToken)
+
+	if *allInstalled && *modelList != "" {
+		fmt.Println("Error: -models and -all are mutually exclusive")
+		os.Exit(2)
+	}
+
+	// Every request uses the same generation settings so runs and models are comparable
+	options := map[string]interface{}{"temperature": *temperature, "seed": *seed}
+	if *numCtx > 0 {
+		options["num_ctx"] = *numCtx
+	}
+
+	fmt.Print("=== Ollama LLM Benchmark Tool ===\n\n")
+
+	host, err := ollama.EnvHost()
+	if *hostFlag != "" {
+		host, err = ollama.ParseHost(*hostFlag)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Check if Ollama is running
+	latency, err := ollama.CheckHealth(host)
+	if err != nil {
+		fmt.Printf("Error: Ollama health check failed (%v)\n", err)
+		if errors.Is(err, ollama.ErrOllamaDown) {
+			fmt.Println("Run: ollama serve")
+		} else {
+			fmt.Println("Check the Ollama server logs or restart it.")
+		}
+		return
+	}
+	fmt.Printf("✓ Ollama at %s responded in %.2f ms\n", host, float64(latency.Microseconds())/1000)
+
+	// Define models to test
+	models := defaultModels
+	if *modelList != "" {
+		models = nil
+		for _, model := range strings.Split(*modelList, ",") {
+			if model = strings.TrimSpace(model); model != "" {
+				models = append(models, model)
+			}
+		}
+		if len(models) == 0 {
+			fmt.Println("Error: -models needs at least one model name")
+			os.Exit(2)
+		}
+	}
+	if *allInstalled {
+		installed, err := ollama.ListModels(host)
+		if err != nil {
+			fmt.Printf("Error: could not list installed models: %v\n", err)
+			return
+		}
+		models = nil
+		for _, m := range installed {
+			models = append(models, m.Name)
+		}
+		if len(models) == 0 {
+			fmt.Println("No models are installed; pull one with: ollama pull llama3.2:1b")
+			return
+		}
+		sort.Strings(models)
+	}
+
+	// Make sure every model is installed, or can be pulled, before any test runs
+	models = ensureModels(host, models)
+	if len(models) == 0 {
+		fmt.Println("\nNone of the requested models is available.")
+		return
+	}
+
+	// Define test cases
+	testCases := []TestCase{
+		{
+			Name:           "Simple Reasoning",
+			Category:       "reasoning",
+			Prompt:         "Explain the concept of recursion in programming in one paragraph.",
+			ExpectedLen:    150,
+			ExpectKeywords: []string{"itself", "base case"},
+		},
+		{
+			Name:           "Code Generation",
+			Category:       "coding",
+			Prompt:         "Write a Python function to calculate the factorial of a number using recursion.",
+			ExpectedLen:    200,
+			ExpectKeywords: []string{"factorial", "return"},
+			ExpectRegex:    `def \w+\(`,
+		},
+		{
+			Name:           "Mathematical Problem",
+			Category:       "math",
+			Prompt:         "If a train travels at 60 mph for 2.5 hours, how far does it travel? Show your work.",
+			ExpectedLen:    100,
+			ExpectKeywords: []string{"miles"},
+			ExpectRegex:    `\b150\b`,
+		},
+		{
+			Name:        "Creative Writing",
+			Category:    "creative",
+			Prompt:      "Write a short haiku about artificial intelligence.",
+			ExpectedLen: 25,
+		},
+		{
+			Name:           "Question Answering",
+			Category:       "qa",
+			Prompt:         "What is the capital of France and what is it famous for?",
+			ExpectedLen:    120,
+			ExpectKeywords: []string{"Paris", "Eiffel"},
+		},
+	}
+
+	// Run benchmarks
+	var comparisons []ModelComparison
+
+	for _, model := range models {
+		fmt.Printf("\n=== Testing Model: %s ===\n", model)
+
+		var results []BenchmarkResult
+		var totalTPS float64
+		var totalTime float64
+		var totalQuality float64
+		successCount := 0
+		scoredCount := 0
+
+		for _, test := range testCases {
+			fmt.Printf("\n  Running test: %s (%s)\n", test.Name, test.Category)
+			result := runBenchmark(host, model, test, options, *stream)
+			results = append(results, result)
+
+			if result.Success {
+				totalTPS += result.TokensPerSecond
+				totalTime += result.TotalTimeMs
+				successCount++
+				ttft := ""
+				if result.Streamed {
+					ttft = fmt.Sprintf(" | TTFT: %.2fms", result.TimeToFirstToken)
+				}
+				quality := ""
+				if result.QualityScore >= 0 {
+					totalQuality += result.QualityScore
+					scoredCount++
+					quality = fmt.Sprintf(" | Quality: %.0f%%", result.QualityScore*100)
+				}
+				fmt.Printf("    ✓ Tokens/sec: %.2f%s | Total time: %.2fms | Tokens: %d%s\n",
+					result.TokensPerSecond, ttft, result.TotalTimeMs, result.TotalTokens, quality)
+			} else {
+				fmt.Printf("    ✗ Error: %s\n", result.Error)
+			}
+		}
+
+		avgTPS := 0.0
+		avgTime := 0.0
+		if successCount > 0 {
+			avgTPS = totalTPS / float64(successCount)
+			avgTime = totalTime / float64(successCount)
+		}
+		avgQuality := -1.0
+		if scoredCount > 0 {
+			avgQuality = totalQuality / float64(scoredCount)
+		}
+
+		comparisons = append(comparisons, ModelComparison{
+			ModelName:       model,
+			AvgTokensPerSec: avgTPS,
+			AvgTotalTimeMs:  avgTime,
+			AvgQuality:      avgQuality,
+			TestResults:     results,
+		})
+	}
+
+	// Display comparison
+	fmt.Print("\n\n=== Model Comparison Summary ===\n\n")
+	displayComparison(comparisons)
+}
+
+// ensureModels pulls the models that aren't installed and returns those that are available, in order.
+// Models that can't be pulled are reported and dropped.
+func ensureModels(host string, models []string) []string {
+	// One /api/tags call for the whole list; if it fails, every model goes through a pull, which only re-checks installed ones
+	installed := map[string]bool{}
+	if list, err := ollama.ListModels(host); err == nil {
+		for _, m := range list {
+			installed[ollama.WithDefaultTag(m.Name)] = true
+		}
+	}
+
+	var available []string
+	for _, model := range models {
+		if installed[ollama.WithDefaultTag(model)] {
+			available = append(available, model)
+			continue
+		}
+		fmt.Printf("Model %s not found. Pulling model...\n", model)
+		if err := ollama.Pull(host, model, true); err != nil {
+			fmt.Printf("Failed to pull %s: %v. Skipping...\n", model, err)
+			if hint := ollama.PullFailureHint(err); hint != "" {
+				fmt.Printf("  → %s\n", hint)
+			}
+			continue
+		}
+		available = append(available, model)
+	}
+	return available
+}
+
+// runBenchmark sends one prompt. With stream set it reads the NDJSON chunks and times the first non-empty one.
+func runBenchmark(host, model string, test TestCase, options map[string]interface{}, stream bool) BenchmarkResult {
+	result := BenchmarkResult{
+		ModelName: model,
+		TestName:  test.Name,
+		Category:  test.Category,
+	}
+
+	req := ollama.GenerateRequest{
+		Model:   model,
+		Prompt:  test.Prompt,
+		Stream:  stream,
+		Options: options,
+	}
+
+	// The first chunk with text marks the time to first token
+	var firstToken time.Time
+	genResp, startTime, err := ollama.Generate(host, req, func(chunk ollama.GenerateResponse, received time.Time) {
+		if chunk.Response != "" && firstToken.IsZero() {
+			firstToken = received
+		}
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if !firstToken.IsZero() {
+		result.TimeToFirstToken = float64(firstToken.Sub(startTime).Microseconds()) / 1000
+		result.Streamed = true
+	}
+
+	totalTime := time.Since(startTime)
+
+	// Calculate metrics
+	result.Success = true
+	result.Response = genResp.Response
+	result.QualityScore = qualityScore(test, genResp.Response, genResp.EvalCount)
+	result.TotalTokens = genResp.EvalCount
+	result.PromptTokens = genResp.PromptEvalCount
+	result.TotalTimeMs = float64(totalTime.Microseconds()) / 1000
+
+	// Tokens per second = eval_count / (eval_duration in nanoseconds) * 10^9
+	if genResp.EvalDuration > 0 {
+		result.TokensPerSecond = float64(genResp.EvalCount) / float64(genResp.EvalDuration) * 1e9
+	}
+
+	result.LoadTimeMs = float64(genResp.LoadDuration) / 1e6
+	result.PromptEvalTimeMs = float64(genResp.PromptEvalDuration) / 1e6
+
+	// Without streaming, approximate time to first token from load + prompt eval time
+	if !result.Streamed && genResp.LoadDuration > 0 && genResp.PromptEvalDuration > 0 {
+		result.TimeToFirstToken = float64(genResp.LoadDuration+genResp.PromptEvalDuration) / 1e6
+	}
+
+	return result
+}
+
+// qualityScore averages the checks the test defines: the share of expected keywords found, whether the
+// regex matches, and how close the token count is to ExpectedLen (shorter/longer as a ratio). -1 if none apply.
+func qualityScore(test TestCase, response string, tokens int) float64 {
+	var total float64
+	checks := 0
+
+	if len(test.ExpectKeywords) > 0 {
+		lower := strings.ToLower(response)
+		hits := 0
+		for _, keyword := range test.ExpectKeywords {
+			if strings.Contains(lower, strings.ToLower(keyword)) {
+				hits++
+			}
+		}
+		total += float64(hits) / float64(len(test.ExpectKeywords))
+		checks++
+	}
+	if test.ExpectRegex != "" {
+		// An invalid pattern counts as a miss rather than aborting the run
+		if matched, err := regexp.MatchString(test.ExpectRegex, response); err == nil && matched {
+			total++
+		}
+		checks++
+	}
+	if test.ExpectedLen > 0 {
+		shorter, longer := float64(tokens), float64(test.ExpectedLen)
+		if shorter > longer {
+			shorter, longer = longer, shorter
+		}
+		total += shorter / longer
+		checks++
+	}
+
+	if checks == 0 {
+		return -1
+	}
+	return total / float64(checks)
+}
+
+// adjustedSpeed discounts tokens/sec by answer quality, so a fast model with wrong answers ranks lower
+func adjustedSpeed(tokensPerSec, quality float64) float64 {
+	if quality < 0 {
+		return tokensPerSec
+	}
+	return tokensPerSec * quality
+}
+
+func displayComparison(comparisons []ModelComparison) {
+	if len(comparisons) == 0 {
+		fmt.Println("No results to display.")
+		return
+	}
+
+	// Overall performance ranking
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		return adjustedSpeed(comparisons[i].AvgTokensPerSec, comparisons[i].AvgQuality) >
+			adjustedSpeed(comparisons[j].AvgTokensPerSec, comparisons[j].AvgQuality)
+	})
+	fmt.Println("Overall Performance Ranking (by avg tokens/sec × quality):")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	for i, comp := range comparisons {
+		quality := " n/a"
+		if comp.AvgQuality >= 0 {
+			quality = fmt.Sprintf("%3.0f%%", comp.AvgQuality*100)
+		}
+		fmt.Printf("%d. %-20s | Avg Speed: %6.2f t/s | Quality: %s | Avg Time: %7.2f ms\n",
+			i+1, comp.ModelName, comp.AvgTokensPerSec, quality, comp.AvgTotalTimeMs)
+	}
+
+	// Category breakdown
+	categories := map[string]bool{}
+	for _, comp := range comparisons {
+		for _, result := range comp.TestResults {
+			categories[result.Category] = true
+		}
+	}
+
+	for category := range categories {
+		fmt.Printf("\n\nCategory: %s\n", category)
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+		for _, comp := range comparisons {
+			for _, result := range comp.TestResults {
+				if result.Category == category && result.Success {
+					fmt.Printf("%-20s | %6.2f t/s | %7.2f ms | %d tokens",
+						comp.ModelName, result.TokensPerSecond, result.TotalTimeMs, result.TotalTokens)
+					if result.QualityScore >= 0 {
+						fmt.Printf(" | quality %.0f%%", result.QualityScore*100)
+					}
+					fmt.Println()
+				}
+			}
+		}
+	}
+
+	// Best model for each category, by speed discounted for quality
+	fmt.Println("\n\nBest Model for Each Category:")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	for category := range categories {
+		bestModel := ""
+		bestSpeed := 0.0
+		bestScore := 0.0
+
+		for _, comp := range comparisons {
+			for _, result := range comp.TestResults {
+				if !result.Success || result.Category != category {
+					continue
+				}
+				if score := adjustedSpeed(result.TokensPerSecond, result.QualityScore); bestModel == "" || score > bestScore {
+					bestScore = score
+					bestSpeed = result.TokensPerSecond
+					bestModel = comp.ModelName
+				}
+			}
+		}
+
+		if bestModel != "" {
+			fmt.Printf("%-15s: %s (%.2f t/s)\n", category, bestModel, bestSpeed)
+		}
+	}
+}
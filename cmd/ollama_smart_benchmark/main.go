@@ -0,0 +1,6260 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/revoxz/LLMtesting/internal/gguf"
+	"github.com/revoxz/LLMtesting/internal/ollama"
+	"github.com/revoxz/LLMtesting/internal/sysinfo"
+	_ "modernc.org/sqlite" // pure-Go SQLite driver for -db, so builds need no cgo
+)
+
+// Config structures
+type Config struct {
+	LLMFamilies          []LLMFamily       `json:"llm_families"`
+	ResourceLimits       ResourceLimits    `json:"resource_limits"`
+	TestSettings         TestSettings      `json:"test_settings"`
+	Include              []string          `json:"include"`
+	Exclude              []string          `json:"exclude"`
+	Hosts                []string          `json:"hosts"`      // Ollama endpoints to benchmark; -host flags take precedence
+	GGUFFiles            map[string]string `json:"gguf_files"` // model tag -> GGUF file it was imported from
+	History              HistorySettings   `json:"history"`
+	ReferenceSystemsFile string            `json:"reference_systems_file"` // known results to express this machine's numbers against
+}
+
+type LLMFamily struct {
+	Name            string `json:"name"`
+	Enabled         bool   `json:"enabled"`
+	TestAllVariants bool   `json:"test_all_variants"`
+	SystemPrompt    string `json:"system_prompt,omitempty"` // overrides test_settings.system_prompt for this family
+}
+
+type ResourceLimits struct {
+	MaxRAMUsagePercent int                   `json:"max_ram_usage_percent"`
+	MinFreeRAMGB       MemorySize            `json:"min_free_ram_gb"`
+	ReserveTiers       []sysinfo.ReserveTier `json:"reserve_tiers"`
+}
+
+// MemorySize is an amount of RAM in GB. Config files may give it as a plain number of GB, as they always could,
+// or as a string with a unit: "4GB", "1.5 GB", "512MB" or "1TB".
+type MemorySize float64
+
+func (m *MemorySize) UnmarshalJSON(data []byte) error {
+	var gb float64
+	if err := json.Unmarshal(data, &gb); err == nil {
+		*m = MemorySize(gb)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("memory size %s must be a number of GB or a string like \"4GB\" or \"512MB\"", data)
+	}
+	size, err := parseMemorySize(s)
+	if err != nil {
+		return err
+	}
+	*m = size
+	return nil
+}
+
+// parseMemorySize reads "4GB", "512 MB", "1.5G" or a bare number of GB; units are binary (1 GB = 1024 MB)
+func parseMemorySize(s string) (MemorySize, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	scale := 1.0
+	for _, unit := range []struct {
+		suffix string
+		scale  float64
+	}{{"TIB", 1024}, {"TB", 1024}, {"GIB", 1}, {"GB", 1}, {"MIB", 1.0 / 1024}, {"MB", 1.0 / 1024}, {"T", 1024}, {"G", 1}, {"M", 1.0 / 1024}} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			scale = unit.scale
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory size %q (use e.g. \"4GB\" or \"512MB\")", s)
+	}
+	return MemorySize(number * scale), nil
+}
+
+// WholeGB rounds up to the whole GB the RAM fit math works in, so a reserve is never smaller than configured
+func (m MemorySize) WholeGB() int64 {
+	return int64(math.Ceil(float64(m)))
+}
+
+func (m MemorySize) String() string {
+	if m > 0 && m < 1 {
+		return fmt.Sprintf("%g MB", float64(m)*1024)
+	}
+	return fmt.Sprintf("%g GB", float64(m))
+}
+
+// validateResourceLimits rejects memory settings that can't be meant, once the machine's RAM is known.
+// Checks that don't need the RAM size are done by validateConfig when the file loads.
+func validateResourceLimits(limits ResourceLimits, totalGB int64) error {
+	if limits.MinFreeRAMGB.WholeGB() > totalGB {
+		err := fmt.Errorf("resource_limits.min_free_ram_gb is %v, more than this machine's %d GB of RAM", limits.MinFreeRAMGB, totalGB)
+		// A bare number is GB; a value in the thousands was most likely meant as MB
+		if limits.MinFreeRAMGB >= 1024 {
+			err = fmt.Errorf("%v; did you mean \"%gMB\"?", err, float64(limits.MinFreeRAMGB))
+		}
+		return err
+	}
+	return nil
+}
+
+type HistorySettings struct {
+	File     string `json:"file"`      // JSONL file each run is appended to; empty disables history
+	KeepRuns int    `json:"keep_runs"` // 0 keeps every run
+	KeepDays int    `json:"keep_days"` // 0 keeps runs of any age
+}
+
+type TestSettings struct {
+	AutoPullModels              bool     `json:"auto_pull_models"`
+	SkipIfInsufficientResources bool     `json:"skip_if_insufficient_resources"`
+	ParallelTesting             bool     `json:"parallel_testing"` // benchmark several models at once, as many as fit in RAM together
+	ThrottleCheckIterations     int      `json:"throttle_check_iterations"`
+	ThrottleThresholdPercent    float64  `json:"throttle_threshold_percent"`
+	CountFailuresAsZero         bool     `json:"count_failures_as_zero"`       // failed tests pull tokens/sec averages down instead of vanishing
+	TokenWeightedAverage        bool     `json:"token_weighted_average"`       // rank by the token-weighted mean instead of the simple mean
+	Stop                        []string `json:"stop"`                         // stop sequences for tests that don't set their own
+	ConcurrentRequests          int      `json:"concurrent_requests"`          // fire this many simultaneous requests per model; 0 or 1 disables
+	MaxRetries                  int      `json:"max_retries"`                  // re-run a failed test up to this many times before recording the failure
+	SystemPrompt                string   `json:"system_prompt"`                // sent as the system field with every test; empty sends none
+	WarmSession                 bool     `json:"warm_session"`                 // load each model once, keep it resident for all its tests, unload it before the next
+	LoadTimeoutSeconds          int      `json:"load_timeout_seconds"`         // skip a model that hasn't loaded after this long; 0 waits indefinitely
+	DiscoveryConcurrency        int      `json:"discovery_concurrency"`        // parallel health checks and metadata fetches across endpoints; 0 or 1 is serial
+	ServerRecoveryWaitSeconds   int      `json:"server_recovery_wait_seconds"` // when Ollama stops mid-run, wait this long for it to return; 0 stops and reports
+	// Ollama generation options sent with every test (e.g. temperature, seed, num_ctx); per-test options override
+	// them. Omitted means deterministicOptions; {} leaves sampling to the server defaults.
+	Options     map[string]interface{} `json:"options"`
+	WarmupRuns  int                    `json:"warmup_runs"`  // untimed requests per model before its tests, so the first test doesn't pay the load
+	RepeatCount int                    `json:"repeat_count"` // run each test this many times and report the mean; 0 or 1 runs it once
+	// Ranking weight per test category, e.g. {"coding": 2, "reasoning": 1.5}; unlisted categories weigh 1, and
+	// an empty map ranks every test equally
+	CategoryWeights map[string]float64 `json:"category_weights"`
+}
+
+// System resources
+type SystemInfo struct {
+	TotalRAMGB       int64
+	AvailableRAMGB   int64
+	AvailableRAMNote string // how AvailableRAMGB was derived, for --explain
+	TotalRAMSource   string // where TotalRAMGB came from (sysctl, /proc/meminfo, cgroup limit or override)
+	Virtualization   string // hypervisor the host runs under (e.g. "kvm", "vmware"), empty on bare metal or when unknown
+	Arch             string
+}
+
+// Published or measured numbers from another machine, keyed by model tag
+type ReferenceSystem struct {
+	Name         string             `json:"name"`
+	Note         string             `json:"note,omitempty"`
+	TokensPerSec map[string]float64 `json:"tokens_per_sec"`
+}
+
+// Test structures
+type TestCase struct {
+	Name     string                 `json:"name"`
+	Prompt   string                 `json:"prompt"`
+	Category string                 `json:"category"`
+	Options  map[string]interface{} `json:"options,omitempty"` // e.g. temperature, num_predict, seed
+	Stop     []string               `json:"stop,omitempty"`    // generation ends at the first of these; defaults to test_settings.stop
+	System   string                 `json:"system,omitempty"`  // system prompt; defaults to the family or test_settings.system_prompt
+	// Rough answer length in tokens, shown next to the measured count; informational only
+	ExpectedLen int `json:"expected_len,omitempty"`
+}
+
+type BenchmarkResult struct {
+	ModelName             string
+	ModelSize             string
+	TestName              string
+	Category              string
+	TokensPerSecond       float64
+	TimeToFirstToken      float64
+	LoadTimeMs            float64 // model load time reported by Ollama; near zero when the model was already resident
+	PromptEvalTimeMs      float64 // server-reported prompt_eval_duration
+	TotalTokens           int
+	PromptTokens          int
+	PromptTokensPerSecond float64 // prefill speed: prompt_eval_count / prompt_eval_duration
+	TotalTimeMs           float64
+	Response              string
+	ResponseChars         int // length of the full response; Response itself may be cut to -max-response-chars in reports
+	Success               bool
+	Error                 string
+	RAMUsedGB             float64
+	Partial               bool                   // stream was cut off; metrics cover only the chunks that arrived
+	Retries               int                    // failed attempts before this result (max_retries only)
+	Repetitions           int                    // successful runs averaged into the metrics (repeat_count); 0 for a single run
+	FailedRepetitions     int                    // repetitions that failed and were left out of the mean
+	TokensPerSecStdDev    float64                // sample standard deviation of tokens/sec across repetitions
+	TokensPerSecMin       float64                // slowest repetition
+	TokensPerSecMax       float64                // fastest repetition
+	Streamed              bool                   // TimeToFirstToken was measured from the first streamed chunk
+	ContextSize           int                    // requested prompt size in tokens for -context-scaling tests; 0 otherwise
+	TokenGaps             *TokenGapStats         // inter-token latency distribution (-trace-tokens only)
+	GPU                   *GPUStats              // GPU load while the test ran (-gpu-sample only, local endpoints)
+	Options               map[string]interface{} // effective generation options sent to Ollama
+	SystemPrompt          string                 // system prompt sent with the test, empty if none
+	OllamaHost            string                 // endpoint the test ran against
+	Host                  HostSnapshot
+}
+
+// GPU readings over one test; -1 marks a figure the source doesn't report (powermetrics has no temperature)
+type GPUStats struct {
+	Source          string // "nvidia-smi" or "powermetrics"
+	Samples         int
+	AvgUtilPercent  float64
+	PeakUtilPercent float64
+	AvgTempC        float64
+	PeakTempC       float64
+	AvgPowerW       float64
+	PeakPowerW      float64
+}
+
+// Time between consecutive streamed tokens, in milliseconds
+type TokenGapStats struct {
+	Gaps     int
+	MinMs    float64
+	MedianMs float64
+	MaxMs    float64
+}
+
+// Host state sampled when a test starts, with swap activity measured across the test
+type HostSnapshot struct {
+	FreeRAMGB float64 // -1 if unavailable
+	LoadAvg1m float64 // -1 if unavailable
+	SwapIns   int64   // pages swapped in while the test ran
+	SwapOuts  int64   // pages swapped out while the test ran
+}
+
+type ModelSummary struct {
+	ModelName            string
+	OllamaHost           string
+	ModelSize            string
+	AvgTokensPerSec      float64 // the mean selected by token_weighted_average, weighted by category_weights if set; used for ranking
+	MeanTokensPerSec     float64 // simple mean of per-test rates
+	WeightedTokensPerSec float64 // per-test rates weighted by generated tokens
+	AvgTotalTimeMs       float64
+	AvgTTFTMs            float64 // mean TimeToFirstToken over the passed tests that report one: what a chat user waits before text appears
+	TestResults          []BenchmarkResult
+	PassedTests          int
+	TotalRetries         int     // retries spent on the tests counted in TestsRetried
+	TestsRetried         int     // tests that failed first and passed on a retry
+	SystemPrompt         string  // default system prompt for this model's tests, empty if none
+	ParamsBillions       float64 // parameter count from Ollama, the GGUF header or the tag; 0 if unknown
+	ParamTokensPerSec    float64 // AvgTokensPerSec x ParamsBillions: how much model the hardware moves per second
+	CanRun               bool
+	SkipReason           string
+	Aliases              []string // other tags pointing at the same model digest
+	Pulled               bool     // downloaded during this run
+	Preloaded            bool     // already resident in Ollama's memory (/api/ps) before this run loaded it; false with -cold-warm
+	ServerStopped        bool     // Ollama stopped responding during this model's tests; later tests and models didn't run
+	Throttle             *ThrottleCheck
+	Concurrency          *ConcurrencyCheck
+	Prefill              *PrefillCheck
+	ColdWarm             *ColdWarmCheck
+	DecodeCurve          []DecodeWindow
+	ContextScaling       []BenchmarkResult      // one result per -context-sizes prompt size, smallest first
+	Embedding            []EmbeddingBatchResult // embedding-only models: throughput per batch size instead of TestResults
+	GPUComparison        *GPUComparison
+	EstimatedRAMGB       int64   // estimateModelRAM's name-based guess
+	MeasuredRAMGB        float64 // resident size reported by /api/ps after the tests; 0 if unavailable
+	Parallel             bool    // ran alongside other models (parallel_testing), so its speed was measured under contention
+}
+
+// Same prompt with default GPU offload vs forced CPU (num_gpu: 0)
+type GPUComparison struct {
+	GPUTokensPerSec float64
+	CPUTokensPerSec float64
+	Speedup         float64
+	CPUBusyCores    float64 // average cores busy while the CPU run generated; -1 if not sampled
+	CPUCores        int
+	Error           string
+}
+
+// Thermal throttling check (repeated generations of the same prompt)
+type ThrottleCheck struct {
+	TokensPerSecond []float64
+	DeclinePercent  float64
+	Monotonic       bool
+	Likely          bool
+	CPUSpeedLimit   int // percent reported by pmset, -1 if unavailable
+}
+
+// First request after evicting the model vs the requests that followed, from the same pass
+type ColdWarmCheck struct {
+	ColdLoadMs       float64
+	ColdTTFTMs       float64
+	ColdTotalMs      float64
+	ColdTokensPerSec float64
+	WarmTTFTMs       float64
+	WarmTotalMs      float64
+	WarmTokensPerSec float64
+	WarmRequests     int
+}
+
+// Prompt ingestion measured in isolation: a huge prompt with num_predict 1
+type PrefillCheck struct {
+	PromptTokens       int
+	PromptTokensPerSec float64
+	Error              string
+}
+
+// The same prompt sent as K simultaneous requests
+type ConcurrencyCheck struct {
+	Requests               int
+	Succeeded              int
+	WallTimeMs             float64
+	AggregateTokensPerSec  float64 // tokens generated by all requests / wall time
+	PerRequestTokensPerSec float64 // mean decode rate each request saw
+}
+
+// Decode speed over a range of generated tokens (streaming mode)
+type DecodeWindow struct {
+	StartToken      int
+	EndToken        int
+	Tokens          int
+	TokensPerSecond float64
+}
+
+// Embedding API structures (/api/embed accepts a batch of inputs)
+type EmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type EmbedResponse struct {
+	Model           string      `json:"model"`
+	Embeddings      [][]float64 `json:"embeddings"`
+	TotalDuration   int64       `json:"total_duration"`
+	LoadDuration    int64       `json:"load_duration"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+}
+
+// Request and response of the older single-input /api/embeddings endpoint
+type LegacyEmbedRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+type LegacyEmbedResponse struct {
+	Embedding []float64 `json:"embedding"`
+}
+
+// Embedding endpoints: /api/embed takes a batch of inputs; servers that predate it only offer /api/embeddings
+const (
+	embedEndpoint       = "/api/embed"
+	legacyEmbedEndpoint = "/api/embeddings"
+)
+
+type EmbeddingBatchResult struct {
+	BatchSize        int
+	Iterations       int
+	AvgLatencyMs     float64
+	EmbeddingsPerSec float64
+	Dimensions       int
+	Success          bool
+	Error            string
+}
+
+// Optional per-model measurements enabled by flags
+type RunOptions struct {
+	DecodeWindows []int // nil unless -decode-curve
+	CPUVsGPU      bool
+	PrefillTokens int   // approximate prompt size for the prefill benchmark; 0 disables it
+	ContextSizes  []int // prompt sizes for the context-length stress test; nil unless -context-scaling
+	ColdWarm      bool
+	Unload        bool // evict each model once its tests and checks are done
+}
+
+// Set by --quiet; progress output is dropped so only the final report remains
+var quiet bool
+
+// Header metadata for models listed under gguf_files, keyed by model tag
+var ggufModels = map[string]*gguf.Info{}
+
+// Set by --stream; generate requests stream NDJSON chunks instead of returning one object
+var streamResponses bool
+
+// Set by --trace-tokens (implies --stream); every streamed token is timestamped
+var traceTokens bool
+
+// Set by --fixed-tokens; every test generates exactly this many tokens (num_predict) so output length is identical
+var fixedTokens int
+
+// Set while parallel_testing runs several models at once: their line-by-line progress would interleave, so it is
+// held back and each model reports one block when it finishes
+var parallelProgress bool
+
+// Set by --gpu-sample; local tests poll GPU utilization, temperature and power while they run
+var gpuSampling bool
+
+// Set from test_settings.warm_session; every request pins the model until it is unloaded explicitly
+var warmSession bool
+
+// Decimal places for tokens/sec, times and other metrics in the human-readable report (--precision)
+var precision = 2
+
+func main() {
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "list" {
+		runListCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "compare" {
+		runCompareCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "estimate" {
+		runEstimateCommand(os.Args[2:])
+		return
+	}
+
+	configFile := flag.String("config", "config.json", "Config file to load (e.g. separate profiles for quick sweeps, full runs and CI)")
+	embedModel := flag.String("embed-model", "", "Benchmark embedding throughput for this model instead of running the generation tests")
+	embedBatchSizes := flag.String("embed-batch-sizes", "1,8,32,64", "Comma-separated batch sizes for the embedding benchmark")
+	embedIterations := flag.Int("embed-iterations", 3, "Timed requests per batch size in the embedding benchmark")
+	decodeCurve := flag.Bool("decode-curve", false, "Stream one long generation per model and report tokens/sec as the context fills")
+	decodeWindows := flag.String("decode-windows", "100,500,2000", "Comma-separated token boundaries for the decode-curve windows")
+	prefill := flag.Bool("prefill", false, "Also send one huge prompt per model with num_predict 1 and report prompt tokens/sec")
+	prefillTokens := flag.Int("prefill-tokens", 4000, "Approximate prompt size in tokens for -prefill")
+	contextScaling := flag.Bool("context-scaling", false, "Also send prompts of growing size per model and report tokens/sec and TTFT at each size")
+	contextSizes := flag.String("context-sizes", "256,1024,4096,8192", "Comma-separated prompt sizes in tokens for -context-scaling")
+	gpuColdCheck := flag.Bool("gpu-cold-check", false, "Report whether the session's very first generation was anomalously slow compared to later models (GPU cold start)")
+	coldWarm := flag.Bool("cold-warm", false, "Unload each model first and report its cold first request next to the warm requests that follow")
+	unload := flag.Bool("unload", false, "Unload each model (keep_alive 0) when it is done, so later models don't compete with it for RAM")
+	cpuVsGPU := flag.Bool("cpu-vs-gpu", false, "Also run one test per model with num_gpu:0 and report the GPU speedup factor")
+	var testNames stringList
+	flag.Var(&testNames, "test", "Run only the test with this name, e.g. \"Code Generation\" (repeatable)")
+	testsFile := flag.String("tests", "", "JSON file with custom test cases (name, prompt, category, options)")
+	explain := flag.Bool("explain", false, "Print the RAM math behind each model's fit decision")
+	dryRun := flag.Bool("dry-run", false, "List the models that would be pulled and tested, with estimated RAM and download size, then exit")
+	compareQuants := flag.String("compare-quants", "", "Benchmark the quantizations of one base model (e.g. llama3.1:8b) and print a side-by-side table")
+	quantSuffixes := flag.String("quants", "instruct-q4_K_M,instruct-q5_K_M,instruct-q8_0", "Comma-separated tag suffixes for -compare-quants, lowest to highest precision")
+	bestFit := flag.String("best-fit", "", "Benchmark only the largest variant of this family that fits in RAM and print a single recommendation")
+	maxModels := flag.Int("max-models", 0, "Benchmark at most this many of the testable models, in -sort order (0 = all)")
+	sortBy := flag.String("sort", "name", "Order of testable models: \"name\", \"size\" (largest first) or \"smallest\"")
+	onlyInstalled := flag.Bool("only-installed", false, "Benchmark only models already installed locally; never pull")
+	var formats formatList
+	flag.Var(&formats, "format", "Write the results as json, csv, markdown, html, text or plain (repeatable; see -out)")
+	outBase := flag.String("out", "results", "Base path for -format outputs; the format's extension is appended")
+	jsonFile := flag.String("json", "", "Write the results as JSON to this file (shorthand for -format json with an exact path)")
+	csvFile := flag.String("csv", "", "Write one CSV row per test result to this file (shorthand for -format csv with an exact path)")
+	mdFile := flag.String("md", "", "Write a Markdown report to this file (shorthand for -format markdown with an exact path)")
+	csvResponses := flag.Bool("csv-responses", false, "Add each test's response text as the last CSV column")
+	dbFile := flag.String("db", "", "Also record the run and every test result in this SQLite database (e.g. bench.db)")
+	signResults := flag.Bool("sign", false, "With JSON output, also write FILE.sha256 over the canonicalized JSON")
+	maxResponseChars := flag.Int("max-response-chars", 2000, "Cut each response stored in reports and history to N characters (token counts and timings are kept)")
+	fullResponses := flag.Bool("full-responses", false, "Store every response in full, e.g. for quality review (overrides -max-response-chars)")
+	verifyFile := flag.String("verify", "", "Check a -json export against its .sha256 file and exit")
+	totalRAMGB := flag.Int64("total-ram-gb", 0, "Use this total RAM instead of detecting it (for restricted environments)")
+	var hostFlags hostList
+	flag.Var(&hostFlags, "host", "Ollama endpoint to benchmark (repeatable; default $OLLAMA_HOST or "+ollama.DefaultHost+")")
+	flag.IntVar(&fixedTokens, "fixed-tokens", 0, "Set num_predict to N for every test so all models generate the same number of tokens (0 = off)")
+	flag.IntVar(&precision, "precision", 2, "Decimal places for tokens/sec and timing metrics in the report")
+	flag.BoolVar(&streamResponses, "stream", false, "Stream generate responses and also measure wall-clock time to first token")
+	flag.BoolVar(&gpuSampling, "gpu-sample", false, "Poll GPU utilization, temperature and power about once a second during each test (nvidia-smi, or powermetrics as root on macOS)")
+	flag.BoolVar(&traceTokens, "trace-tokens", false, "Debug: timestamp every streamed token and print the min/median/max gap between tokens (implies -stream)")
+	authToken := flag.String("auth-token", "", "Send \"Authorization: Bearer TOKEN\" with every Ollama request, for endpoints behind an auth proxy")
+	flag.Var(ollama.HeaderFlag{}, "header", "Add a header to every Ollama request, as Name=Value (repeatable)")
+	flag.DurationVar(&ollama.RequestClient.Timeout, "timeout", ollama.DefaultRequestTimeout, "Give up on a generate or embed request after this long, e.g. 90s or 5m (0 = never)")
+	flag.BoolVar(&quiet, "quiet", false, "Suppress per-test progress and print only the final ranking and recommendations")
+	flag.Parse()
+	ollama.SetAuthToken(*authToken)
+	// Retry notices from the Ollama client follow -quiet like the rest of the progress output
+	ollama.Logf = progressf
+
+	// Integrity check of an earlier export; nothing is benchmarked
+	if *verifyFile != "" {
+		if err := verifyJSONResults(*verifyFile); err != nil {
+			fmt.Printf("✗ %s: %v\n", *verifyFile, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ %s matches %s.sha256\n", *verifyFile, *verifyFile)
+		return
+	}
+
+	if *dryRun && (*embedModel != "" || *bestFit != "" || *compareQuants != "") {
+		fmt.Println("Error: -dry-run lists the regular model sweep; it can't be combined with -embed-model, -best-fit or -compare-quants")
+		os.Exit(2)
+	}
+
+	if precision < 0 {
+		precision = 0
+	}
+	if traceTokens {
+		streamResponses = true
+	}
+
+	progressf("=== Smart Ollama LLM Benchmark ===\n\n")
+
+	// Load config
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		if errors.Is(err, errConfigNotFound) {
+			fmt.Fprintf(os.Stderr, "\nA minimal config to start from:\n%s", sampleConfig)
+		}
+		return
+	}
+
+	if *onlyInstalled {
+		config.TestSettings.AutoPullModels = false
+	}
+	warmSession = config.TestSettings.WarmSession
+	// With max_retries the test loop does all retrying, so a flaky test makes at most 1+max_retries requests and
+	// each retry is counted; without it, the client still retries transient failures on its own
+	if config.TestSettings.MaxRetries > 0 {
+		ollama.GenerateRetries = 0
+	}
+
+	// Custom models imported from local GGUF files are sized from the file header, not the tag
+	for model, file := range config.GGUFFiles {
+		info, err := gguf.ReadInfo(file)
+		if err != nil {
+			fmt.Printf("⚠️  Warning: cannot inspect GGUF file for %s: %v\n", model, err)
+			continue
+		}
+		ggufModels[model] = info
+		progressf("GGUF %s: %s, %s params, %s, context %d, ~%d GB RAM\n", model, info.Architecture,
+			gguf.FormatParameterCount(info.ParameterCount), info.Quantization, info.ContextLength, gguf.RAMGB(info))
+	}
+
+	// Get system info
+	sysInfo, err := getSystemInfo(config.ResourceLimits, *totalRAMGB)
+	if err != nil {
+		fmt.Printf("Error getting system info: %v\n", err)
+		return
+	}
+
+	if err := validateResourceLimits(config.ResourceLimits, sysInfo.TotalRAMGB); err != nil {
+		fmt.Printf("Error in %s: %v\n", *configFile, err)
+		return
+	}
+
+	progressf("System Info:\n")
+	progressf("  Total RAM: %d GB (%s)\n", sysInfo.TotalRAMGB, sysInfo.TotalRAMSource)
+	progressf("  Available RAM: %d GB\n", sysInfo.AvailableRAMGB)
+	progressf("  Architecture: %s\n", sysInfo.Arch)
+	if sysInfo.Virtualization != "" {
+		progressf("  ⚠️  Virtualized host (%s): CPU and memory are shared with a hypervisor, so results reflect the VM,\n", sysInfo.Virtualization)
+		progressf("     not the bare hardware, and may be lower or noisier than expected\n")
+	}
+	progressf("\n")
+
+	hosts := []string(hostFlags)
+	if len(hosts) == 0 {
+		for _, h := range config.Hosts {
+			host, err := ollama.ParseHost(h)
+			if err != nil {
+				fmt.Printf("Error in config hosts: %v\n", err)
+				os.Exit(1)
+			}
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		host, err := ollama.EnvHost()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		hosts = []string{host}
+	}
+
+	// Check that every Ollama endpoint is up; unreachable ones are dropped from the run. The checks, and the
+	// model list fetch that warms the cache for discovery, run concurrently and are reported in host order.
+	workers := config.TestSettings.DiscoveryConcurrency
+	latencies := make([]time.Duration, len(hosts))
+	healthErrs := make([]error, len(hosts))
+	serverSettings := make([]map[string]string, len(hosts))
+	settingsSources := make([]string, len(hosts))
+	forEachBounded(len(hosts), workers, func(i int) {
+		latencies[i], healthErrs[i] = ollama.CheckHealth(hosts[i])
+		if healthErrs[i] == nil {
+			serverSettings[i], settingsSources[i] = readServerSettings(hosts[i])
+			getInstalledModels(hosts[i])
+		}
+	})
+	var reachable []string
+	for i, h := range hosts {
+		latency, err := latencies[i], healthErrs[i]
+		if err != nil {
+			fmt.Printf("Error: Ollama health check failed for %s (%v)\n", h, err)
+			if errors.Is(err, ollama.ErrOllamaDown) {
+				fmt.Println("Run: ollama serve")
+			} else {
+				fmt.Println("Check the Ollama server logs or restart it.")
+			}
+			continue
+		}
+		progressf("✓ Ollama at %s responded in %s ms\n", h, formatMetric(float64(latency.Microseconds())/1000))
+		if serverSettings[i] != nil {
+			progressf("  Server settings (%s): %s\n", settingsSources[i], formatServerSettings(serverSettings[i]))
+		}
+		reachable = append(reachable, h)
+	}
+	if len(reachable) == 0 {
+		return
+	}
+	hosts = reachable
+	progressf("\n")
+
+	// Model discovery and embedding mode use the first endpoint. Resource filtering uses this machine's RAM, so it
+	// only applies to local endpoints.
+	host := hosts[0]
+	var remoteHosts []string
+	filterHost := ""
+	for _, h := range hosts {
+		if !isLocalHost(h) {
+			remoteHosts = append(remoteHosts, h)
+		} else if filterHost == "" {
+			filterHost = h
+		}
+	}
+
+	var windowBounds []int
+	if *decodeCurve {
+		windowBounds, err = parseIntList(*decodeWindows)
+		if err != nil {
+			fmt.Printf("Error: invalid -decode-windows: %v\n", err)
+			return
+		}
+		sort.Ints(windowBounds)
+	}
+	opts := RunOptions{DecodeWindows: windowBounds, CPUVsGPU: *cpuVsGPU, ColdWarm: *coldWarm, Unload: *unload}
+	if *prefill {
+		opts.PrefillTokens = *prefillTokens
+	}
+	if *contextScaling {
+		opts.ContextSizes, err = parseIntList(*contextSizes)
+		if err != nil {
+			fmt.Printf("Error: invalid -context-sizes: %v\n", err)
+			return
+		}
+		sort.Ints(opts.ContextSizes)
+	}
+
+	// Define test cases
+	testCases := []TestCase{
+		{
+			Name:     "Simple Reasoning",
+			Category: "reasoning",
+			Prompt:   "Explain the concept of recursion in programming in one paragraph.",
+		},
+		{
+			Name:     "Code Generation",
+			Category: "coding",
+			Prompt:   "Write a Python function to calculate the factorial of a number using recursion.",
+		},
+		{
+			Name:     "Mathematical Problem",
+			Category: "math",
+			Prompt:   "If a train travels at 60 mph for 2.5 hours, how far does it travel? Show your work.",
+		},
+		{
+			Name:     "Creative Writing",
+			Category: "creative",
+			Prompt:   "Write a short haiku about artificial intelligence.",
+		},
+		{
+			Name:     "Question Answering",
+			Category: "qa",
+			Prompt:   "What is the capital of France and what is it famous for?",
+		},
+	}
+
+	if *testsFile != "" {
+		loaded, err := loadTestCases(*testsFile)
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("⚠️  Warning: tests file %s not found; using the %d built-in tests\n", *testsFile, len(testCases))
+		} else if err != nil {
+			fmt.Printf("Error loading tests: %v\n", err)
+			return
+		} else {
+			testCases = loaded
+			progressf("Loaded %d test cases from %s\n", len(testCases), *testsFile)
+		}
+	}
+	if len(testNames) > 0 {
+		testCases, err = selectTests(testCases, testNames)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+	for i := range testCases {
+		if len(testCases[i].Stop) == 0 {
+			testCases[i].Stop = config.TestSettings.Stop
+		}
+		testCases[i].Options = mergeOptions(config.TestSettings.Options, testCases[i].Options)
+		if fixedTokens > 0 {
+			testCases[i].Options = mergeOptions(testCases[i].Options, map[string]interface{}{"num_predict": fixedTokens})
+		}
+	}
+
+	// Also used for embedding-only models found in the regular model list
+	embedBatches, err := parseIntList(*embedBatchSizes)
+	if err != nil {
+		fmt.Printf("Error: invalid -embed-batch-sizes: %v\n", err)
+		return
+	}
+
+	// Embedding batch benchmark mode
+	if *embedModel != "" {
+		summary := benchmarkEmbeddingModel(host, *embedModel, embedBatches, *embedIterations, config)
+		if summary.Embedding == nil {
+			fmt.Printf("%s: %s\n", *embedModel, summary.SkipReason)
+			return
+		}
+		displayEmbeddingResults(*embedModel, summary.Embedding)
+		displayNewlyPulled([]ModelSummary{summary})
+		return
+	}
+
+	// Single decisive answer: measure only the largest variant of one family that fits
+	if *bestFit != "" {
+		model, ramGB, ok := pickBestFit(host, *bestFit, sysInfo, config)
+		if !ok {
+			fmt.Printf("No variant of %s fits in %d GB of available RAM with %d GB headroom%s.\n",
+				*bestFit, sysInfo.AvailableRAMGB, config.ResourceLimits.MinFreeRAMGB.WholeGB(), ramCapNote(sysInfo, config.ResourceLimits))
+			return
+		}
+		progressf("Best fit for %s: %s (~%d GB of %d GB available)\n", *bestFit, model, ramGB, sysInfo.AvailableRAMGB)
+		summary := benchmarkModel(host, model, testCases, config, opts)
+		displayBestFit(summary, ramGB, sysInfo)
+		displayNewlyPulled([]ModelSummary{summary})
+		return
+	}
+
+	// Focused view: one base model, each quantization side by side
+	if *compareQuants != "" {
+		var suffixes []string
+		for _, suffix := range strings.Split(*quantSuffixes, ",") {
+			if suffix = strings.TrimSpace(suffix); suffix != "" {
+				suffixes = append(suffixes, suffix)
+			}
+		}
+		if len(suffixes) == 0 {
+			fmt.Println("Error: -quants needs at least one tag suffix")
+			return
+		}
+		summaries := runQuantComparison(host, *compareQuants, suffixes, testCases, config, opts)
+		displayQuantComparison(*compareQuants, suffixes, summaries)
+		displayNewlyPulled(summaries)
+		return
+	}
+
+	// Nothing installed and nothing may be pulled: the config is fine, the machine just has no models yet
+	if installed, err := getInstalledModels(host); err == nil && len(installed) == 0 && !config.TestSettings.AutoPullModels {
+		printNoModelsGuidance(config, sysInfo, *onlyInstalled)
+		return
+	}
+
+	// Fail fast when even the smallest enabled model can't fit, instead of discovering an empty list. A remote
+	// endpoint may have the RAM this machine lacks, so the check only runs when every endpoint is local.
+	if config.TestSettings.SkipIfInsufficientResources && len(remoteHosts) == 0 {
+		if model, ramGB, ok := smallestEnabledModel(host, config); ok {
+			if reason := resourceShortfall(ramGB, sysInfo, config.ResourceLimits); reason != "" {
+				printNothingFitsGuidance(model, reason, sysInfo, config)
+				return
+			}
+		}
+	}
+
+	// Get all available models from Ollama library
+	progressf("Fetching available models from Ollama library...\n")
+	availableModels := getOllamaLibraryModels(host, config)
+
+	// Apply the include/exclude patterns from config
+	var filteredOut int
+	availableModels, filteredOut = applyModelFilters(availableModels, config)
+	if filteredOut > 0 {
+		progressf("Include/exclude patterns removed %d model variants\n", filteredOut)
+	}
+
+	// Never download anything: drop every candidate that isn't already local
+	if *onlyInstalled {
+		var excluded int
+		availableModels, excluded = filterInstalledModels(host, availableModels)
+		progressf("--only-installed: excluded %d candidate models that are not installed\n", excluded)
+	}
+
+	if len(availableModels) == 0 {
+		fmt.Println("No models found to test. Please check your config.json")
+		return
+	}
+
+	progressf("\nFound %d model variants to test:\n", len(availableModels))
+	for _, model := range availableModels {
+		progressf("  - %s\n", model)
+	}
+
+	// Filter models based on this machine's resources, for the local endpoints only
+	skipReasons := map[string]string{}
+	if filterHost != "" {
+		var testable []string
+		testable, skipReasons = filterModelsByResources(filterHost, availableModels, sysInfo, config, *explain)
+
+		progressf("\n%d models are testable on your system:\n", len(testable))
+		for _, model := range testable {
+			progressf("  ✓ %s\n", model)
+		}
+
+		if len(availableModels) > len(testable) {
+			progressf("\n%d models skipped due to insufficient resources:\n", len(availableModels)-len(testable))
+			for _, model := range availableModels {
+				if reason, skipped := skipReasons[model]; skipped {
+					progressf("  ✗ %s: %s\n", model, reason)
+				}
+			}
+		}
+	}
+	if len(remoteHosts) > 0 {
+		progressf("\nResource limits are not applied to %s: this machine's RAM says nothing about a remote host,\n",
+			strings.Join(remoteHosts, ", "))
+		progressf("so every discovered model is tried there\n")
+	}
+
+	// Tags sharing a digest are the same model; benchmark each one once
+	candidates, aliases := dedupeModels(host, availableModels)
+	for _, model := range candidates {
+		if len(aliases[model]) > 0 && skipReasons[model] == "" {
+			progressf("  ↺ %s is the same model as %s, testing once\n", strings.Join(aliases[model], ", "), model)
+		}
+	}
+
+	// Order candidates, then keep per kind of endpoint the ones it may run and the first -max-models of those
+	if err := sortModels(host, candidates, *sortBy); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	limitModels := func(models []string, label string) []string {
+		if *maxModels > 0 && len(models) > *maxModels {
+			progressf("\n-max-models %d%s: skipping %s\n", *maxModels, label, strings.Join(models[*maxModels:], ", "))
+			return models[:*maxModels]
+		}
+		return models
+	}
+	var localModels []string
+	for _, model := range candidates {
+		if _, skipped := skipReasons[model]; !skipped {
+			localModels = append(localModels, model)
+		}
+	}
+	remoteModels := candidates
+	if filterHost != "" && len(remoteHosts) > 0 {
+		localModels = limitModels(localModels, " (local)")
+		remoteModels = limitModels(remoteModels, " (remote)")
+	} else {
+		localModels = limitModels(localModels, "")
+		remoteModels = localModels
+	}
+	modelsFor := func(h string) []string {
+		if isLocalHost(h) {
+			return localModels
+		}
+		return remoteModels
+	}
+
+	// Stop here with -dry-run, before anything is pulled or generated
+	if *dryRun {
+		printDryRun(hosts, modelsFor, sysInfo, config)
+		return
+	}
+
+	// Run benchmarks
+	var summaries []ModelSummary
+
+	for _, host := range hosts {
+		if len(hosts) > 1 {
+			progressf("\n\n=== Host: %s ===\n", host)
+		}
+		testableModels := modelsFor(host)
+		runModel := func(model string) ModelSummary {
+			var summary ModelSummary
+			if isEmbeddingModel(model) {
+				summary = benchmarkEmbeddingModel(host, model, embedBatches, *embedIterations, config)
+			} else {
+				summary = benchmarkModel(host, model, testCases, config, opts)
+			}
+			summary.Aliases = aliases[model]
+			return summary
+		}
+
+		if config.TestSettings.ParallelTesting && len(testableModels) > 1 && !isLocalHost(host) {
+			progressf("parallel_testing: %s is remote and its RAM is unknown here, so its models run one at a time\n", host)
+		} else if config.TestSettings.ParallelTesting && len(testableModels) > 1 {
+			workers := parallelWorkers(host, testableModels, sysInfo, config)
+			progressf("\nparallel_testing: up to %d models at once\n", workers)
+			summaries = append(summaries, benchmarkModelsParallel(host, testableModels, workers, runModel)...)
+			continue
+		}
+
+		for i, model := range testableModels {
+			summary := runModel(model)
+			summaries = append(summaries, summary)
+
+			// Report what was gathered instead of grinding through requests that can only fail
+			if summary.ServerStopped {
+				fmt.Printf("Stopping the run on %s; reporting the results gathered so far\n", host)
+				if notRun := testableModels[i+1:]; len(notRun) > 0 {
+					fmt.Printf("Not run: %s\n", strings.Join(notRun, ", "))
+				}
+				for _, rest := range testableModels[i+1:] {
+					summaries = append(summaries, ModelSummary{
+						ModelName:  rest,
+						OllamaHost: host,
+						SkipReason: "Not run: Ollama stopped during the benchmark",
+					})
+				}
+				break
+			}
+		}
+	}
+
+	// Display results
+	progressf("\n\n=== Benchmark Results ===\n\n")
+	var references []ReferenceSystem
+	if config.ReferenceSystemsFile != "" {
+		references, err = loadReferenceSystems(config.ReferenceSystemsFile)
+		if err != nil {
+			fmt.Printf("⚠️  Warning: cannot load reference systems: %v\n", err)
+		}
+	}
+	displayResults(summaries, sysInfo, config.TestSettings.TokenWeightedAverage, config.TestSettings.CategoryWeights, references)
+	for _, s := range summaries {
+		if s.Embedding == nil {
+			continue
+		}
+		label := s.ModelName
+		if len(hosts) > 1 {
+			label += " @ " + s.OllamaHost
+		}
+		displayEmbeddingResults(label, s.Embedding)
+	}
+	if *gpuColdCheck {
+		displayGPUColdStart(detectGPUColdStart(summaries))
+	}
+	displayNewlyPulled(summaries)
+
+	run := RunRecord{
+		SchemaVersion:  resultsSchemaVersion,
+		Timestamp:      time.Now(),
+		Arch:           sysInfo.Arch,
+		TotalRAMGB:     sysInfo.TotalRAMGB,
+		Virtualization: sysInfo.Virtualization,
+		FixedTokens:    fixedTokens,
+		Metadata:       collectRunMetadata(hosts, *configFile, config, sysInfo),
+		Summaries:      summaries,
+	}
+	responseLimit := *maxResponseChars
+	if *fullResponses {
+		responseLimit = -1
+	}
+	run.Summaries = truncateResponses(summaries, responseLimit)
+
+	progressf("\nRun metadata:\n")
+	for _, line := range metadataLines(run) {
+		progressf("  %s\n", line)
+	}
+
+	// -json FILE and -csv FILE are shorthands with an exact path; -format outputs share the -out base
+	outputFiles := []string{}
+	outputReporters := map[string]Reporter{}
+	addOutput := func(filename string, reporter Reporter) {
+		if _, isCSV := reporter.(csvReporter); isCSV && *csvResponses {
+			reporter = csvReporter{Responses: true}
+		}
+		if _, ok := outputReporters[filename]; !ok {
+			outputFiles = append(outputFiles, filename)
+			outputReporters[filename] = reporter
+		}
+	}
+	if *jsonFile != "" {
+		addOutput(*jsonFile, reporters["json"])
+	}
+	if *csvFile != "" {
+		addOutput(*csvFile, reporters["csv"])
+	}
+	if *mdFile != "" {
+		addOutput(*mdFile, reporters["markdown"])
+	}
+	for _, name := range formats {
+		addOutput(reportPath(*outBase, reporters[name]), reporters[name])
+	}
+	for _, filename := range outputFiles {
+		reporter := outputReporters[filename]
+		_, isJSON := reporter.(jsonReporter)
+		_, isCSV := reporter.(csvReporter)
+		if err := writeReport(filename, reporter, run, *signResults); err != nil {
+			fmt.Printf("\n⚠️  Warning: could not write %s: %v\n", filename, err)
+		} else if *signResults && isJSON {
+			progressf("\nWrote %s and %s.sha256\n", filename, filename)
+		} else if isCSV {
+			progressf("\nWrote %s and %s.meta.txt\n", filename, filename)
+		} else {
+			progressf("\nWrote %s\n", filename)
+		}
+	}
+
+	if config.History.File != "" {
+		if err := appendHistory(config.History, run); err != nil {
+			fmt.Printf("\n⚠️  Warning: could not record run in %s: %v\n", config.History.File, err)
+		}
+	}
+	if *dbFile != "" {
+		if err := recordRunSQLite(*dbFile, run); err != nil {
+			fmt.Printf("\n⚠️  Warning: could not record run in %s: %v\n", *dbFile, err)
+		} else {
+			progressf("\nRecorded run in %s\n", *dbFile)
+		}
+	}
+}
+
+// aggregateRepetitions folds repeated runs of one test into a single result: timings and token counts are
+// averaged over the successful runs, and tokens/sec also gets its spread. The first run supplies everything
+// else (response, options, host snapshot). samples[0] must have succeeded.
+func aggregateRepetitions(samples []BenchmarkResult) BenchmarkResult {
+	result := samples[0]
+	var ok []BenchmarkResult
+	for _, s := range samples {
+		if s.Success {
+			ok = append(ok, s)
+		}
+	}
+	n := float64(len(ok))
+	result.Repetitions = len(ok)
+	result.FailedRepetitions = len(samples) - len(ok)
+
+	var tps, ttft, total, load, promptEval, promptTPS, tokens, ram float64
+	result.TokensPerSecMin = ok[0].TokensPerSecond
+	result.TokensPerSecMax = ok[0].TokensPerSecond
+	for _, s := range ok {
+		tps += s.TokensPerSecond
+		ttft += s.TimeToFirstToken
+		total += s.TotalTimeMs
+		load += s.LoadTimeMs
+		promptEval += s.PromptEvalTimeMs
+		promptTPS += s.PromptTokensPerSecond
+		tokens += float64(s.TotalTokens)
+		if s.RAMUsedGB > ram {
+			ram = s.RAMUsedGB
+		}
+		if s.TokensPerSecond < result.TokensPerSecMin {
+			result.TokensPerSecMin = s.TokensPerSecond
+		}
+		if s.TokensPerSecond > result.TokensPerSecMax {
+			result.TokensPerSecMax = s.TokensPerSecond
+		}
+	}
+	result.TokensPerSecond = tps / n
+	result.TimeToFirstToken = ttft / n
+	result.Streamed = ok[0].Streamed
+	result.TotalTimeMs = total / n
+	result.LoadTimeMs = load / n
+	result.PromptEvalTimeMs = promptEval / n
+	result.PromptTokensPerSecond = promptTPS / n
+	result.TotalTokens = int(math.Round(tokens / n))
+	result.RAMUsedGB = ram
+
+	if len(ok) > 1 {
+		var squares float64
+		for _, s := range ok {
+			d := s.TokensPerSecond - result.TokensPerSecond
+			squares += d * d
+		}
+		result.TokensPerSecStdDev = math.Sqrt(squares / (n - 1))
+	}
+	return result
+}
+
+// Warmups per model when the config has no warmup_runs
+const defaultWarmupRuns = 1
+
+// Untimed prompt and token cap for test_settings.warmup_runs
+const (
+	warmupPrompt = "Reply with one word: ready"
+	warmupTokens = 8
+)
+
+// benchmarkModel runs the test matrix and the optional per-model measurements against one endpoint
+func benchmarkModel(host string, model string, testCases []TestCase, config *Config, opts RunOptions) ModelSummary {
+	progressf("\n=== Testing Model: %s ===\n", model)
+
+	// Check if model is installed locally
+	pulled := false
+	if !checkModelInstalled(host, model) {
+		if !config.TestSettings.AutoPullModels {
+			progressf("Model %s not installed. Skipping (auto_pull disabled)...\n", model)
+			return ModelSummary{
+				ModelName:  model,
+				OllamaHost: host,
+				CanRun:     false,
+				SkipReason: "Model not installed",
+			}
+		}
+		progressf("Model %s not installed. Pulling model...\n", model)
+		if err := pullModel(host, model); err != nil {
+			progressf("Failed to pull %s: %v. Skipping...\n", model, err)
+			if hint := ollama.PullFailureHint(err); hint != "" {
+				progressf("  → %s\n", hint)
+			}
+			return ModelSummary{
+				ModelName:  model,
+				OllamaHost: host,
+				CanRun:     false,
+				SkipReason: fmt.Sprintf("Failed to pull model: %v", err),
+			}
+		}
+		pulled = true
+	}
+
+	// A model left resident by an earlier run or client skips the cold load, which shows up in the timings
+	resident, preloaded := findLoadedModel(host, model)
+	if preloaded && !opts.ColdWarm {
+		placement := "CPU only"
+		if resident.SizeVRAM > 0 {
+			placement = fmt.Sprintf("%.1f GB on GPU", float64(resident.SizeVRAM)/(1024*1024*1024))
+		}
+		progressf("  Already loaded (%.1f GB, %s); the first test won't include a cold load\n",
+			float64(resident.Size)/(1024*1024*1024), placement)
+	}
+
+	// A model too large for the machine can take minutes to fail; give the load its own deadline
+	if seconds := config.TestSettings.LoadTimeoutSeconds; seconds > 0 {
+		progressf("  Loading %s (deadline %ds)...\n", model, seconds)
+		if err := loadModel(host, model, "", time.Duration(seconds)*time.Second); err != nil {
+			progressf("  ✗ %v. Skipping...\n", err)
+			return ModelSummary{
+				ModelName:  model,
+				OllamaHost: host,
+				CanRun:     false,
+				SkipReason: err.Error(),
+				Pulled:     pulled,
+			}
+		}
+	}
+
+	// Evict the model so the first test pays the full startup cost
+	if opts.ColdWarm {
+		progressf("  Unloading %s so the first test starts cold...\n", model)
+		if err := setKeepAlive(host, model, "0"); err != nil {
+			progressf("  ⚠️  Warning: unload failed (%v); the cold numbers may be warm\n", err)
+		}
+	}
+
+	// Load once and pin the model so no test pays for a reload; it is unloaded before the next model.
+	// With -cold-warm the first test does the loading instead.
+	if warmSession {
+		if !opts.ColdWarm {
+			progressf("  Loading %s for a warm session...\n", model)
+			if err := setKeepAlive(host, model, keepAliveWarm); err != nil {
+				progressf("  ⚠️  Warning: preload failed (%v); the first test will include the load time\n", err)
+			}
+		}
+	}
+	// A warm session always ends with an unload; -unload asks for the same without pinning
+	if warmSession || opts.Unload {
+		defer func() {
+			progressf("  Unloading %s...\n", model)
+			if err := setKeepAlive(host, model, "0"); err != nil {
+				progressf("  ⚠️  Warning: could not unload %s: %v\n", model, err)
+			}
+		}()
+	}
+
+	// Some models refuse or ramble without a system prompt
+	systemPrompt := systemPromptFor(model, config)
+	if systemPrompt != "" {
+		tests := make([]TestCase, len(testCases))
+		for i, test := range testCases {
+			tests[i] = test
+			if tests[i].System == "" {
+				tests[i].System = systemPrompt
+			}
+		}
+		testCases = tests
+	}
+
+	// Throwaway requests pull the weights into memory before anything is timed. They reuse the first test's
+	// options, since a different num_ctx would make Ollama reload the model for the real tests.
+	if runs := config.TestSettings.WarmupRuns; runs > 0 && len(testCases) > 0 && !opts.ColdWarm {
+		warmup := TestCase{
+			Name:     "warmup",
+			Category: "warmup",
+			Prompt:   warmupPrompt,
+			System:   testCases[0].System,
+			Options:  mergeOptions(testCases[0].Options, map[string]interface{}{"num_predict": warmupTokens}),
+		}
+		for i := 1; i <= runs; i++ {
+			progressf("  Warmup %d/%d...\n", i, runs)
+			if result := runBenchmark(host, model, warmup, nil); !result.Success {
+				progressf("  ⚠️  Warning: warmup failed (%s); the first test may include the load time\n", result.Error)
+			}
+		}
+	}
+
+	var results []BenchmarkResult
+	var totalTPS float64
+	var totalTime float64
+	var totalTTFT float64
+	ttftCount := 0 // passed tests with a TTFT; a cached prompt or a cut-off stream can leave it at 0
+	successCount := 0
+
+	serverStopped := false
+	for i := 0; i < len(testCases); i++ {
+		test := testCases[i]
+		progressf("\n  Running test: %s (%s)\n", test.Name, test.Category)
+		result := runBenchmark(host, model, test, nil)
+		for attempt := 1; !result.Success && attempt <= config.TestSettings.MaxRetries; attempt++ {
+			progressf("    ↻ Retry %d/%d after error: %s\n", attempt, config.TestSettings.MaxRetries, result.Error)
+			time.Sleep(time.Duration(attempt) * time.Second)
+			result = runBenchmark(host, model, test, nil)
+			result.Retries = attempt
+		}
+		// Extra runs of a test that worked once; their mean replaces the single measurement
+		if repeats := config.TestSettings.RepeatCount; repeats > 1 && result.Success {
+			samples := []BenchmarkResult{result}
+			for n := 2; n <= repeats; n++ {
+				progressf("    Repetition %d/%d...\n", n, repeats)
+				samples = append(samples, runBenchmark(host, model, test, nil))
+			}
+			result = aggregateRepetitions(samples)
+		}
+		results = append(results, result)
+
+		if result.Success {
+			totalTPS += result.TokensPerSecond
+			totalTime += result.TotalTimeMs
+			if result.TimeToFirstToken > 0 {
+				totalTTFT += result.TimeToFirstToken
+				ttftCount++
+			}
+			successCount++
+			progressf("    ✓ Tokens/sec: %s | Total time: %sms | Tokens: %d | RAM: %.1f GB\n",
+				formatMetric(result.TokensPerSecond), formatMetric(result.TotalTimeMs), result.TotalTokens, result.RAMUsedGB)
+			progressf("      Host: %s\n", formatHostSnapshot(result.Host))
+			if test.ExpectedLen > 0 {
+				progressf("      Length: %d tokens (expected ~%d)\n", result.TotalTokens, test.ExpectedLen)
+			}
+			if result.Repetitions > 1 {
+				progressf("      Mean of %d runs: ± %s t/s (min %s, max %s)\n", result.Repetitions,
+					formatMetric(result.TokensPerSecStdDev), formatMetric(result.TokensPerSecMin), formatMetric(result.TokensPerSecMax))
+			}
+			if result.FailedRepetitions > 0 {
+				progressf("      ⚠️  %d repetition(s) failed and were left out of the mean\n", result.FailedRepetitions)
+			}
+			if result.Streamed {
+				progressf("      First token after %s ms\n", formatMetric(result.TimeToFirstToken))
+			}
+			if result.Partial {
+				progressf("      ⚠️  Partial result: %s\n", result.Error)
+			}
+			if result.TokenGaps != nil {
+				progressf("      Token gaps (%d): min %s ms | median %s ms | max %s ms\n", result.TokenGaps.Gaps,
+					formatMetric(result.TokenGaps.MinMs), formatMetric(result.TokenGaps.MedianMs), formatMetric(result.TokenGaps.MaxMs))
+			}
+			if result.GPU != nil {
+				progressf("      GPU peak (%s, %d samples): %s\n", result.GPU.Source, result.GPU.Samples, formatGPUPeaks(result.GPU))
+			}
+		} else {
+			progressf("    ✗ Error: %s\n", result.Error)
+
+			// A test that failed even after its retries may mean the server is gone, not the model
+			if _, err := ollama.CheckHealth(host); err != nil {
+				fmt.Printf("\n⚠️  Ollama at %s appears to have stopped (%v)\n", host, err)
+				if !waitForOllama(host, config.TestSettings.ServerRecoveryWaitSeconds) {
+					serverStopped = true
+					break
+				}
+				fmt.Printf("✓ Ollama is back; re-running %s\n", test.Name)
+				results = results[:len(results)-1]
+				i--
+			}
+		}
+	}
+
+	avgTPS := 0.0
+	avgTime := 0.0
+	avgTTFT := 0.0
+	if successCount > 0 {
+		avgTPS = totalTPS / float64(successCount)
+		avgTime = totalTime / float64(successCount)
+	}
+	if ttftCount > 0 {
+		avgTTFT = totalTTFT / float64(ttftCount)
+	}
+	// A failed test produced zero tokens; optionally let it count so flaky models don't look better than they are
+	if config.TestSettings.CountFailuresAsZero && len(results) > 0 {
+		avgTPS = totalTPS / float64(len(results))
+	}
+
+	// Only a retry that turned a failure into a pass makes a test flaky; one that never passed is a failure
+	totalRetries := 0
+	testsRetried := 0
+	for _, r := range results {
+		if r.Success && r.Retries > 0 {
+			totalRetries += r.Retries
+			testsRetried++
+		}
+	}
+
+	// Long outputs count for more in the weighted mean, so it reflects sustained throughput
+	weightedTPS := 0.0
+	totalTokens := 0
+	for _, r := range results {
+		if r.Success {
+			weightedTPS += r.TokensPerSecond * float64(r.TotalTokens)
+			totalTokens += r.TotalTokens
+		}
+	}
+	if totalTokens > 0 {
+		weightedTPS /= float64(totalTokens)
+	}
+
+	summary := ModelSummary{
+		ModelName:            model,
+		OllamaHost:           host,
+		ModelSize:            extractModelSize(model),
+		AvgTokensPerSec:      avgTPS,
+		MeanTokensPerSec:     avgTPS,
+		WeightedTokensPerSec: weightedTPS,
+		AvgTotalTimeMs:       avgTime,
+		AvgTTFTMs:            avgTTFT,
+		TestResults:          results,
+		PassedTests:          successCount,
+		Pulled:               pulled,
+		Preloaded:            preloaded && !opts.ColdWarm,
+		TotalRetries:         totalRetries,
+		TestsRetried:         testsRetried,
+		SystemPrompt:         systemPrompt,
+		CanRun:               successCount > 0,
+	}
+	if config.TestSettings.TokenWeightedAverage {
+		summary.AvgTokensPerSec = weightedTPS
+	}
+	if len(config.TestSettings.CategoryWeights) > 0 {
+		summary.AvgTokensPerSec = categoryWeightedTPS(results, config.TestSettings)
+	}
+	// Keep what was measured, but nothing else can be asked of a server that is gone
+	if serverStopped {
+		summary.ServerStopped = true
+		return summary
+	}
+
+	summary.ParamsBillions = modelParamsBillions(host, model)
+	summary.ParamTokensPerSec = summary.AvgTokensPerSec * summary.ParamsBillions
+
+	// The model is still loaded, so its real footprint can be checked against the heuristic
+	summary.EstimatedRAMGB = estimateModelRAM(model)
+	if summary.CanRun {
+		if size, err := getLoadedModelSize(host, model); err == nil {
+			summary.MeasuredRAMGB = float64(size) / (1024 * 1024 * 1024)
+		}
+	}
+
+	if opts.ColdWarm && summary.CanRun {
+		summary.ColdWarm = coldWarmFromResults(results)
+		if summary.ColdWarm != nil {
+			progressf("\n  Cold: load %s ms, TTFT %s ms, %s t/s | Warm: TTFT %s ms, %s t/s\n",
+				formatMetric(summary.ColdWarm.ColdLoadMs), formatMetric(summary.ColdWarm.ColdTTFTMs), formatMetric(summary.ColdWarm.ColdTokensPerSec),
+				formatMetric(summary.ColdWarm.WarmTTFTMs), formatMetric(summary.ColdWarm.WarmTokensPerSec))
+		}
+	}
+
+	// Repeat one prompt to detect sustained-load throttling
+	if config.TestSettings.ThrottleCheckIterations > 1 && summary.CanRun {
+		progressf("\n  Running throttle check: %d iterations of %s\n",
+			config.TestSettings.ThrottleCheckIterations, testCases[0].Name)
+		summary.Throttle = runThrottleCheck(host, model, testCases[0], config.TestSettings)
+		if summary.Throttle.Likely {
+			progressf("    ⚠️  Thermal throttling likely (%.1f%% decline)\n", summary.Throttle.DeclinePercent)
+		} else {
+			progressf("    ✓ No sustained decline (%.1f%% change)\n", summary.Throttle.DeclinePercent)
+		}
+	}
+
+	// Aggregate throughput when several clients share the model
+	if config.TestSettings.ConcurrentRequests > 1 && summary.CanRun {
+		progressf("\n  Running concurrency check: %d parallel requests of %s\n",
+			config.TestSettings.ConcurrentRequests, testCases[0].Name)
+		summary.Concurrency = runConcurrencyCheck(host, model, testCases[0], config.TestSettings.ConcurrentRequests)
+		progressf("    %d/%d succeeded | Aggregate: %s t/s | Per request: %s t/s\n",
+			summary.Concurrency.Succeeded, summary.Concurrency.Requests,
+			formatMetric(summary.Concurrency.AggregateTokensPerSec), formatMetric(summary.Concurrency.PerRequestTokensPerSec))
+	}
+
+	// Prompt ingestion speed on its own, which dominates long-context and RAG workloads
+	if opts.PrefillTokens > 0 && summary.CanRun {
+		progressf("\n  Running prefill benchmark: ~%d prompt tokens\n", opts.PrefillTokens)
+		summary.Prefill = runPrefillCheck(host, model, opts.PrefillTokens)
+		if summary.Prefill.Error != "" {
+			progressf("    ✗ Error: %s\n", summary.Prefill.Error)
+		} else {
+			progressf("    %d prompt tokens at %s t/s\n", summary.Prefill.PromptTokens, formatMetric(summary.Prefill.PromptTokensPerSec))
+		}
+	}
+
+	// Find where speed falls off as the prompt grows
+	if len(opts.ContextSizes) > 0 && summary.CanRun {
+		progressf("\n  Running context scaling: %s prompt tokens\n", joinInts(opts.ContextSizes))
+		summary.ContextScaling = runContextScaling(host, model, opts.ContextSizes)
+		for _, r := range summary.ContextScaling {
+			if !r.Success {
+				progressf("    ~%d tokens: ✗ %s\n", r.ContextSize, r.Error)
+				continue
+			}
+			progressf("    ~%d tokens: %s t/s | TTFT %s ms\n", r.ContextSize, formatMetric(r.TokensPerSecond), formatMetric(r.TimeToFirstToken))
+		}
+	}
+
+	// Stream a long generation to chart decode speed as the KV cache fills
+	if len(opts.DecodeWindows) > 0 && summary.CanRun {
+		progressf("\n  Running decode curve: up to %d tokens\n", opts.DecodeWindows[len(opts.DecodeWindows)-1])
+		curve, err := runDecodeCurve(host, model, opts.DecodeWindows)
+		if err != nil {
+			progressf("    ✗ Error: %v\n", err)
+		}
+		summary.DecodeCurve = curve
+		for _, w := range curve {
+			progressf("    Tokens %d-%d: %s t/s\n", w.StartToken, w.EndToken, formatMetric(w.TokensPerSecond))
+		}
+	}
+
+	// Quantify GPU acceleration by forcing the same prompt onto the CPU
+	if opts.CPUVsGPU && summary.CanRun {
+		progressf("\n  Running CPU vs GPU comparison: %s\n", testCases[0].Name)
+		summary.GPUComparison = runGPUComparison(host, model, testCases[0])
+		if summary.GPUComparison.Error != "" {
+			progressf("    ✗ Error: %s\n", summary.GPUComparison.Error)
+		} else {
+			progressf("    GPU: %s t/s | CPU: %s t/s | Speedup: %sx\n", formatMetric(summary.GPUComparison.GPUTokensPerSec),
+				formatMetric(summary.GPUComparison.CPUTokensPerSec), formatMetric(summary.GPUComparison.Speedup))
+		}
+	}
+
+	return summary
+}
+
+// systemPromptFor returns the system prompt of the longest family name prefixing the model,
+// falling back to test_settings.system_prompt
+func systemPromptFor(model string, config *Config) string {
+	prompt := config.TestSettings.SystemPrompt
+	matched := ""
+	for _, family := range config.LLMFamilies {
+		if family.SystemPrompt != "" && strings.HasPrefix(model, family.Name) && len(family.Name) > len(matched) {
+			prompt = family.SystemPrompt
+			matched = family.Name
+		}
+	}
+	return prompt
+}
+
+// coldWarmFromResults splits a model's tests into the cold first request and the warm rest;
+// nil if the first test failed or no later test succeeded
+func coldWarmFromResults(results []BenchmarkResult) *ColdWarmCheck {
+	if len(results) < 2 || !results[0].Success {
+		return nil
+	}
+	cold := results[0]
+	check := &ColdWarmCheck{
+		ColdLoadMs:       cold.LoadTimeMs,
+		ColdTTFTMs:       cold.TimeToFirstToken,
+		ColdTotalMs:      cold.TotalTimeMs,
+		ColdTokensPerSec: cold.TokensPerSecond,
+	}
+	for _, r := range results[1:] {
+		if r.Success {
+			check.WarmTTFTMs += r.TimeToFirstToken
+			check.WarmTotalMs += r.TotalTimeMs
+			check.WarmTokensPerSec += r.TokensPerSecond
+			check.WarmRequests++
+		}
+	}
+	if check.WarmRequests == 0 {
+		return nil
+	}
+	n := float64(check.WarmRequests)
+	check.WarmTTFTMs /= n
+	check.WarmTotalMs /= n
+	check.WarmTokensPerSec /= n
+	return check
+}
+
+// Options that make generation repeatable, so answers from different quants (or runs) can be compared.
+// Also the default for test_settings.options.
+var deterministicOptions = map[string]interface{}{"temperature": 0, "seed": 42}
+
+// runQuantComparison benchmarks base+"-"+suffix for each suffix with deterministic sampling
+func runQuantComparison(host string, base string, suffixes []string, testCases []TestCase, config *Config, opts RunOptions) []ModelSummary {
+	tests := make([]TestCase, len(testCases))
+	for i, test := range testCases {
+		tests[i] = test
+		tests[i].Options = mergeOptions(test.Options, deterministicOptions)
+	}
+
+	var summaries []ModelSummary
+	for _, suffix := range suffixes {
+		summaries = append(summaries, benchmarkModel(host, base+"-"+suffix, tests, config, opts))
+	}
+	return summaries
+}
+
+// displayQuantComparison prints speed, RAM and agreement with the highest-precision quant (the last suffix).
+// Agreement is the average word overlap between each test's answers, a cheap proxy for quantization loss.
+func displayQuantComparison(base string, suffixes []string, summaries []ModelSummary) {
+	fmt.Printf("\n\n=== Quant Comparison: %s ===\n\n", base)
+	fmt.Printf("%-20s | %12s | %6s | %s\n", "Quant", "Tokens/sec", "RAM", "Agreement")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	sizes := make(map[string]int64)
+	if len(summaries) > 0 {
+		sizes = installedSizes(summaries[0].OllamaHost)
+	}
+
+	reference := summaries[len(summaries)-1]
+	for i, s := range summaries {
+		if !s.CanRun {
+			reason := s.SkipReason
+			if reason == "" {
+				reason = "every test failed"
+			}
+			fmt.Printf("%-20s | %s\n", suffixes[i], reason)
+			continue
+		}
+
+		agreement := "reference"
+		if i < len(summaries)-1 {
+			agreement = "n/a"
+			if score, ok := responseAgreement(s, reference); ok {
+				agreement = fmt.Sprintf("%.0f%%", score*100)
+			}
+		}
+		ramGB, _ := modelRAMRequirement(s.ModelName, sizes)
+		fmt.Printf("%-20s | %8s t/s | %3d GB | %s\n", suffixes[i], formatMetric(s.AvgTokensPerSec), ramGB, agreement)
+	}
+}
+
+// responseAgreement averages the word overlap of the tests both summaries passed
+func responseAgreement(s, reference ModelSummary) (float64, bool) {
+	referenceResponses := make(map[string]string)
+	for _, r := range reference.TestResults {
+		if r.Success {
+			referenceResponses[r.TestName] = r.Response
+		}
+	}
+
+	total := 0.0
+	count := 0
+	for _, r := range s.TestResults {
+		if ref, ok := referenceResponses[r.TestName]; ok && r.Success {
+			total += wordOverlap(r.Response, ref)
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return total / float64(count), true
+}
+
+// wordOverlap is the Jaccard similarity of the lowercased word sets of a and b
+func wordOverlap(a, b string) float64 {
+	wordsA := make(map[string]bool)
+	for _, w := range strings.Fields(strings.ToLower(a)) {
+		wordsA[w] = true
+	}
+	wordsB := make(map[string]bool)
+	for _, w := range strings.Fields(strings.ToLower(b)) {
+		wordsB[w] = true
+	}
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+
+	shared := 0
+	for w := range wordsA {
+		if wordsB[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wordsA)+len(wordsB)-shared)
+}
+
+// sortModels orders models in place by name or by RAM requirement
+func sortModels(host string, models []string, by string) error {
+	sizes := installedSizes(host)
+	ram := func(model string) int64 {
+		gb, _ := modelRAMRequirement(model, sizes)
+		return gb
+	}
+
+	switch by {
+	case "name":
+		sort.Strings(models)
+	case "size":
+		sort.SliceStable(models, func(i, j int) bool { return ram(models[i]) > ram(models[j]) })
+	case "smallest":
+		sort.SliceStable(models, func(i, j int) bool { return ram(models[i]) < ram(models[j]) })
+	default:
+		return fmt.Errorf("unknown -sort %q (use name, size or smallest)", by)
+	}
+	return nil
+}
+
+// pickBestFit returns the variant of family with the largest RAM requirement that still fits the resource limits.
+// Candidates are the known variants plus any installed tags of the family.
+func pickBestFit(host string, family string, sysInfo *SystemInfo, config *Config) (string, int64, bool) {
+	candidates := append([]string{}, getCommonVariants(family)...)
+	sizes := make(map[string]int64)
+	if installed, err := getInstalledModels(host); err == nil {
+		for _, m := range installed {
+			sizes[m.Name] = m.Size
+			if strings.HasPrefix(m.Name, family+":") {
+				candidates = append(candidates, m.Name)
+			}
+		}
+	}
+
+	best := ""
+	var bestRAM int64
+	for _, model := range candidates {
+		ramGB, _ := modelRAMRequirement(model, sizes)
+		if resourceShortfall(ramGB, sysInfo, config.ResourceLimits) == "" && ramGB > bestRAM {
+			best, bestRAM = model, ramGB
+		}
+	}
+	return best, bestRAM, best != ""
+}
+
+func displayBestFit(summary ModelSummary, ramGB int64, sysInfo *SystemInfo) {
+	fmt.Printf("\n\n=== Best Fit: %s ===\n", summary.ModelName)
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	if !summary.CanRun {
+		reason := summary.SkipReason
+		if reason == "" {
+			reason = "every test failed"
+		}
+		fmt.Printf("✗ %s is the largest variant that fits, but it could not be benchmarked: %s\n", summary.ModelName, reason)
+		return
+	}
+	fmt.Printf("✓ Use %s on this machine\n", summary.ModelName)
+	fmt.Printf("  RAM: ~%d GB of %d GB available\n", ramGB, sysInfo.AvailableRAMGB)
+	fmt.Printf("  Speed: %s t/s mean, %s t/s token-weighted | Avg time: %s ms | Passed: %d/%d\n",
+		formatMetric(summary.MeanTokensPerSec), formatMetric(summary.WeightedTokensPerSec),
+		formatMetric(summary.AvgTotalTimeMs), summary.PassedTests, len(summary.TestResults))
+}
+
+// printNoModelsGuidance suggests what to pull, picking the largest variant of each enabled family that fits in RAM
+func printNoModelsGuidance(config *Config, sysInfo *SystemInfo, onlyInstalled bool) {
+	fmt.Println("No models are installed in Ollama, and auto-pull is disabled.")
+	if onlyInstalled {
+		fmt.Println("Either drop -only-installed and set \"auto_pull_models\": true in config.json, or pull models yourself.")
+	} else {
+		fmt.Println("Either set \"auto_pull_models\": true in config.json, or pull models yourself.")
+	}
+
+	var recommended []string
+	for _, family := range config.LLMFamilies {
+		if !family.Enabled {
+			continue
+		}
+		best := ""
+		var bestRAM int64
+		for _, variant := range getCommonVariants(family.Name) {
+			ram := estimateModelRAM(variant)
+			if resourceShortfall(ram, sysInfo, config.ResourceLimits) == "" && ram > bestRAM {
+				best, bestRAM = variant, ram
+			}
+		}
+		if best != "" {
+			recommended = append(recommended, best)
+		}
+	}
+
+	if len(recommended) == 0 {
+		fmt.Printf("\nNo variant of your enabled families fits in %d GB of available RAM; enable a smaller family in config.json.\n",
+			sysInfo.AvailableRAMGB)
+		return
+	}
+	fmt.Printf("\nRecommended for your %d GB of available RAM:\n", sysInfo.AvailableRAMGB)
+	for _, model := range recommended {
+		fmt.Printf("  ollama pull %s\n", model)
+	}
+}
+
+// smallestEnabledModel returns the candidate with the lowest RAM requirement among the known variants and
+// installed tags of the enabled families, after include/exclude patterns; false if there are no candidates
+func smallestEnabledModel(host string, config *Config) (string, int64, bool) {
+	sizes := installedSizes(host)
+	var candidates []string
+	for _, family := range config.LLMFamilies {
+		if !family.Enabled {
+			continue
+		}
+		candidates = append(candidates, getCommonVariants(family.Name)...)
+		for name := range sizes {
+			if strings.HasPrefix(name, family.Name+":") {
+				candidates = append(candidates, name)
+			}
+		}
+	}
+	candidates, _ = applyModelFilters(candidates, config)
+
+	smallest := ""
+	var smallestRAM int64
+	for _, model := range candidates {
+		ram, _ := modelRAMRequirement(model, sizes)
+		if smallest == "" || ram < smallestRAM || (ram == smallestRAM && model < smallest) {
+			smallest, smallestRAM = model, ram
+		}
+	}
+	return smallest, smallestRAM, smallest != ""
+}
+
+// printNothingFitsGuidance explains why not even the smallest enabled model fits and names known variants that would
+func printNothingFitsGuidance(smallest string, reason string, sysInfo *SystemInfo, config *Config) {
+	fmt.Printf("No enabled model fits in this machine's RAM: the smallest, %s, %s\n", smallest, reason)
+	fmt.Printf("(%d GB available: %s).\n", sysInfo.AvailableRAMGB, sysInfo.AvailableRAMNote)
+
+	var fitting []string
+	for _, variants := range commonVariants {
+		for _, variant := range variants {
+			if resourceShortfall(estimateModelRAM(variant), sysInfo, config.ResourceLimits) == "" {
+				fitting = append(fitting, variant)
+			}
+		}
+	}
+	sort.Slice(fitting, func(i, j int) bool {
+		ri, rj := estimateModelRAM(fitting[i]), estimateModelRAM(fitting[j])
+		if ri != rj {
+			return ri > rj
+		}
+		return fitting[i] < fitting[j]
+	})
+	if len(fitting) > 5 {
+		fitting = fitting[:5]
+	}
+
+	if len(fitting) == 0 {
+		fmt.Println("No known model variant fits; lower min_free_ram_gb, raise max_ram_usage_percent or run on a machine with more RAM.")
+		return
+	}
+	fmt.Printf("Enable a smaller family or variant in config.json, e.g. %s,\n", strings.Join(fitting, ", "))
+	fmt.Println("or use a lower quantization (e.g. a q4_K_M tag instead of q8_0) of the model you want.")
+}
+
+func formatHostSnapshot(h HostSnapshot) string {
+	freeRAM := "n/a"
+	if h.FreeRAMGB >= 0 {
+		freeRAM = fmt.Sprintf("%.1f GB", h.FreeRAMGB)
+	}
+	load := "n/a"
+	if h.LoadAvg1m >= 0 {
+		load = fmt.Sprintf("%.2f", h.LoadAvg1m)
+	}
+	return fmt.Sprintf("free RAM %s | load %s | swap in/out %d/%d pages", freeRAM, load, h.SwapIns, h.SwapOuts)
+}
+
+// formatMetric is the single place metric values are rendered for the console
+func formatMetric(value float64) string {
+	return strconv.FormatFloat(value, 'f', precision, 64)
+}
+
+// runListCommand prints an inventory of installed models without running any generation
+func runListCommand(args []string) {
+	listFlags := flag.NewFlagSet("list", flag.ExitOnError)
+	sortBy := listFlags.String("sort", "size", "Sort by \"size\" (largest first) or \"name\"")
+	hostFlag := listFlags.String("host", "", "Ollama endpoint to list (default $OLLAMA_HOST or "+ollama.DefaultHost+")")
+	authToken := listFlags.String("auth-token", "", "Send \"Authorization: Bearer TOKEN\" with every Ollama request")
+	listFlags.Var(ollama.HeaderFlag{}, "header", "Add a header to every Ollama request, as Name=Value (repeatable)")
+	listFlags.Parse(args)
+	ollama.SetAuthToken(*authToken)
+	host, err := ollama.EnvHost()
+	if *hostFlag != "" {
+		host, err = ollama.ParseHost(*hostFlag)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !checkOllamaRunning(host) {
+		fmt.Println("Error: Ollama is not running. Please start Ollama first.")
+		fmt.Println("Run: ollama serve")
+		return
+	}
+
+	installed, err := getInstalledModels(host)
+	if err != nil {
+		fmt.Printf("Error listing models: %v\n", err)
+		return
+	}
+	if len(installed) == 0 {
+		fmt.Println("No models installed. Pull one with: ollama pull <model>")
+		return
+	}
+
+	switch *sortBy {
+	case "size":
+		sort.Slice(installed, func(i, j int) bool { return installed[i].Size > installed[j].Size })
+	case "name":
+		sort.Slice(installed, func(i, j int) bool { return installed[i].Name < installed[j].Name })
+	default:
+		fmt.Printf("Error: unknown sort %q (use size or name)\n", *sortBy)
+		return
+	}
+
+	sizes := make(map[string]int64)
+	var totalSize int64
+	for _, m := range installed {
+		sizes[m.Name] = m.Size
+		totalSize += m.Size
+	}
+
+	fmt.Printf("%-30s | %9s | %-16s | %s\n", "Model", "Disk", "Modified", "Est. RAM")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	for _, m := range installed {
+		ramGB, _ := modelRAMRequirement(m.Name, sizes)
+		fmt.Printf("%-30s | %6.1f GB | %-16s | ~%d GB\n",
+			m.Name, float64(m.Size)/(1024*1024*1024), m.ModifiedAt.Format("2006-01-02 15:04"), ramGB)
+	}
+	fmt.Printf("\n%d models, %.1f GB on disk\n", len(installed), float64(totalSize)/(1024*1024*1024))
+}
+
+// runEstimateCommand answers "will this model fit?" offline, from the tag alone: the name-based RAM estimate
+// against a given (or detected) RAM size, the OS reserve tiers and min_free_ram_gb from the config
+func runEstimateCommand(args []string) {
+	estimateFlags := flag.NewFlagSet("estimate", flag.ExitOnError)
+	ramGB := estimateFlags.Int64("ram", 0, "Total RAM of the target machine in GB (default: this machine's)")
+	configFile := estimateFlags.String("config", "config.json", "Config file for reserve_tiers, min_free_ram_gb and max_ram_usage_percent (defaults apply if it is missing)")
+	estimateFlags.Usage = func() {
+		fmt.Fprintln(estimateFlags.Output(), "Usage: ollama_smart_benchmark estimate MODEL [MODEL...] [-ram GB] [-config FILE]")
+		estimateFlags.PrintDefaults()
+	}
+
+	// Allow flags before and after the model names, e.g. "estimate qwen2.5:32b-q5_K_M -ram 48"
+	var models []string
+	for {
+		estimateFlags.Parse(args)
+		if estimateFlags.NArg() == 0 {
+			break
+		}
+		models = append(models, estimateFlags.Arg(0))
+		args = estimateFlags.Args()[1:]
+	}
+	if len(models) == 0 {
+		estimateFlags.Usage()
+		return
+	}
+
+	limits := ResourceLimits{MaxRAMUsagePercent: defaultMaxRAMUsagePercent, MinFreeRAMGB: 4}
+	if config, err := loadConfig(*configFile); err == nil {
+		limits = config.ResourceLimits
+	} else if !errors.Is(err, errConfigNotFound) {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+	sysInfo, err := getSystemInfo(limits, *ramGB)
+	if err != nil {
+		fmt.Printf("Error: %v (pass -ram GB)\n", err)
+		return
+	}
+	if err := validateResourceLimits(limits, sysInfo.TotalRAMGB); err != nil {
+		fmt.Printf("Error in %s: %v\n", *configFile, err)
+		return
+	}
+	minFree := limits.MinFreeRAMGB.WholeGB()
+
+	fmt.Printf("Target: %s = %d GB available%s\n", sysInfo.AvailableRAMNote, sysInfo.AvailableRAMGB, ramCapNote(sysInfo, limits))
+	for _, model := range models {
+		q4GB, quant, factor := estimateModelRAMDetail(model)
+		required := estimateModelRAM(model)
+		headroom := sysInfo.AvailableRAMGB - required
+
+		fmt.Printf("\n%s\n", model)
+		fmt.Printf("  Estimated RAM: %d GB (Q4 %d GB x %.2f for %s)\n", required, q4GB, factor, strings.ToUpper(quant))
+		fmt.Printf("  Headroom: %d GB available - %d GB = %d GB (min_free_ram_gb: %d GB)\n",
+			sysInfo.AvailableRAMGB, required, headroom, minFree)
+		// Same verdict as the benchmark's resource filter, naming the limit that fails
+		shortfall := resourceShortfall(required, sysInfo, limits)
+		if shortfall == "" {
+			fmt.Printf("  ✓ Fits with %d GB to spare\n", headroom)
+		} else if headroom >= 0 {
+			fmt.Printf("  ⚠️  Loads, but the benchmark would skip it: %s\n", shortfall)
+		} else {
+			fmt.Printf("  ✗ Does not fit: %d GB short; %s\n", -headroom, shortfall)
+		}
+	}
+}
+
+func progressf(format string, a ...interface{}) {
+	if quiet || parallelProgress {
+		return
+	}
+	fmt.Printf(format, a...)
+}
+
+// loadConfig reads the config file, naming the resolved path when it is missing or malformed
+func loadConfig(filename string) (*Config, error) {
+	resolved, err := filepath.Abs(filename)
+	if err != nil {
+		resolved = filename
+	}
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s (pass -config PATH)", errConfigNotFound, resolved)
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: %w", resolved, err)
+	}
+
+	// Defaults for keys the file leaves out; an explicit value, including 0, replaces them
+	config := Config{
+		TestSettings:   TestSettings{WarmupRuns: defaultWarmupRuns},
+		ResourceLimits: ResourceLimits{MaxRAMUsagePercent: defaultMaxRAMUsagePercent},
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %v", resolved, describeJSONError(data, err))
+	}
+	if err := validateConfig(&config); err != nil {
+		return nil, fmt.Errorf("%s: %v", resolved, err)
+	}
+
+	if config.TestSettings.Options == nil {
+		config.TestSettings.Options = mergeOptions(nil, deterministicOptions)
+	}
+
+	// Categories are matched case-insensitively, like test names
+	weights := make(map[string]float64, len(config.TestSettings.CategoryWeights))
+	for category, weight := range config.TestSettings.CategoryWeights {
+		weights[strings.ToLower(category)] = weight
+	}
+	config.TestSettings.CategoryWeights = weights
+
+	return &config, nil
+}
+
+var errConfigNotFound = errors.New("config file not found")
+
+// Printed to stderr when the config file is missing
+const sampleConfig = `{
+  "llm_families": [
+    {"name": "llama3.2", "enabled": true, "test_all_variants": true},
+    {"name": "qwen2.5", "enabled": true, "test_all_variants": false}
+  ],
+  "resource_limits": {
+    "max_ram_usage_percent": 70,
+    "min_free_ram_gb": 4
+  },
+  "test_settings": {
+    "auto_pull_models": true,
+    "skip_if_insufficient_resources": true,
+    "max_retries": 0,
+    "warmup_runs": 1
+  }
+}
+`
+
+// validateConfig checks the settings that json.Unmarshal accepts but the benchmark can't use, and reports every
+// problem at once so a config can be fixed in one pass
+func validateConfig(config *Config) error {
+	var problems []string
+	enabled := 0
+	for i, family := range config.LLMFamilies {
+		if strings.TrimSpace(family.Name) == "" {
+			problems = append(problems, fmt.Sprintf("llm_families[%d]: name is empty", i))
+		}
+		if family.Enabled {
+			enabled++
+		}
+	}
+	if len(config.LLMFamilies) == 0 {
+		problems = append(problems, "llm_families: no families are listed")
+	} else if enabled == 0 {
+		problems = append(problems, "llm_families: no family is enabled; set \"enabled\": true on at least one")
+	}
+
+	limits := config.ResourceLimits
+	if limits.MaxRAMUsagePercent < 1 || limits.MaxRAMUsagePercent > 100 {
+		problems = append(problems, fmt.Sprintf("resource_limits.max_ram_usage_percent must be between 1 and 100 (got %d)",
+			limits.MaxRAMUsagePercent))
+	}
+	if limits.MinFreeRAMGB < 0 {
+		problems = append(problems, fmt.Sprintf("resource_limits.min_free_ram_gb must not be negative (got %v)", limits.MinFreeRAMGB))
+	}
+	for i, tier := range limits.ReserveTiers {
+		if tier.UpToGB < 0 {
+			problems = append(problems, fmt.Sprintf("resource_limits.reserve_tiers[%d]: up_to_gb must not be negative (got %d)",
+				i, tier.UpToGB))
+		}
+	}
+
+	for _, pattern := range append(append([]string{}, config.Include...), config.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid include/exclude pattern %q: %v", pattern, err))
+		}
+	}
+	var categories []string
+	for category := range config.TestSettings.CategoryWeights {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		if config.TestSettings.CategoryWeights[category] < 0 {
+			problems = append(problems, fmt.Sprintf("test_settings.category_weights: weight for %q must not be negative", category))
+		}
+	}
+
+	switch len(problems) {
+	case 0:
+		return nil
+	case 1:
+		return errors.New(problems[0])
+	}
+	return fmt.Errorf("%d problems:\n  - %s", len(problems), strings.Join(problems, "\n  - "))
+}
+
+// How UnmarshalTypeError's Value kinds read in a sentence
+var jsonValueKinds = map[string]string{
+	"bool": "true or false", "string": "a string", "number": "a number", "array": "a list", "object": "an object",
+}
+
+// describeJSONError turns a decoding error into one that names the line and, for a wrong type, the setting and
+// the kind of value it takes, e.g. "line 5: llm_families.enabled is a string but must be true or false"
+func describeJSONError(data []byte, err error) error {
+	line := func(offset int64) int {
+		if offset > int64(len(data)) {
+			offset = int64(len(data))
+		}
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("line %d: %v", line(syntaxErr.Offset), err)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		expected := "a " + typeErr.Type.String()
+		switch typeErr.Type.Kind() {
+		case reflect.Bool:
+			expected = "true or false"
+		case reflect.Int, reflect.Int64, reflect.Float64:
+			expected = "a number"
+		case reflect.String:
+			expected = "a string"
+		case reflect.Slice:
+			expected = "a list"
+		case reflect.Map, reflect.Struct:
+			expected = "an object"
+		}
+		got := strings.SplitN(typeErr.Value, " ", 2)[0]
+		if described, ok := jsonValueKinds[got]; ok {
+			got = described
+		}
+		hint := ""
+		if got == "a string" && typeErr.Type.Kind() != reflect.String {
+			hint = " (remove the quotes)"
+		}
+		return fmt.Errorf("line %d: %s is %s but must be %s%s", line(typeErr.Offset), typeErr.Field, got, expected, hint)
+	}
+	return err
+}
+
+// categoryWeightedTPS averages per-test tokens/sec with each test weighted by its category in category_weights (1 if
+// the category isn't listed) and, with token_weighted_average, also by the tokens it generated. Failed tests count
+// as 0 t/s with count_failures_as_zero and are left out otherwise.
+func categoryWeightedTPS(results []BenchmarkResult, settings TestSettings) float64 {
+	var sum, totalWeight float64
+	for _, r := range results {
+		if !r.Success && !settings.CountFailuresAsZero {
+			continue
+		}
+		weight, ok := settings.CategoryWeights[strings.ToLower(r.Category)]
+		if !ok {
+			weight = 1
+		}
+		if settings.TokenWeightedAverage {
+			weight *= float64(r.TotalTokens)
+		}
+		if r.Success {
+			sum += r.TokensPerSecond * weight
+		}
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return 0
+	}
+	return sum / totalWeight
+}
+
+// formatCategoryWeights lists the weights sorted by category, e.g. "coding ×2, reasoning ×1.5"
+func formatCategoryWeights(weights map[string]float64) string {
+	categories := make([]string, 0, len(weights))
+	for category := range weights {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	parts := make([]string, len(categories))
+	for i, category := range categories {
+		parts[i] = fmt.Sprintf("%s ×%g", category, weights[category])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func loadTestCases(filename string) ([]TestCase, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var testCases []TestCase
+	if err := json.Unmarshal(data, &testCases); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if err := validateTestCases(testCases); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return testCases, nil
+}
+
+// validateTestCases requires at least one test, and a prompt, category and unique name for each.
+// Names are compared case-insensitively, as -test selects them.
+func validateTestCases(testCases []TestCase) error {
+	if len(testCases) == 0 {
+		return errors.New("no test cases")
+	}
+	seen := make(map[string]int)
+	for i, test := range testCases {
+		switch {
+		case strings.TrimSpace(test.Name) == "":
+			return fmt.Errorf("test %d has no name", i+1)
+		case strings.TrimSpace(test.Prompt) == "":
+			return fmt.Errorf("test %q has no prompt", test.Name)
+		case strings.TrimSpace(test.Category) == "":
+			return fmt.Errorf("test %q has no category", test.Name)
+		}
+		key := strings.ToLower(test.Name)
+		if first, ok := seen[key]; ok {
+			return fmt.Errorf("duplicate test name %q (tests %d and %d)", test.Name, first, i+1)
+		}
+		seen[key] = i + 1
+		if test.ExpectedLen < 0 {
+			return fmt.Errorf("test %q: expected_len must not be negative", test.Name)
+		}
+	}
+	return nil
+}
+
+func getSystemInfo(limits ResourceLimits, totalRAMOverrideGB int64) (*SystemInfo, error) {
+	info := &SystemInfo{
+		Arch: runtime.GOARCH,
+	}
+
+	// Get total RAM; -total-ram-gb skips detection entirely
+	if totalRAMOverrideGB > 0 {
+		info.TotalRAMGB = totalRAMOverrideGB
+		info.TotalRAMSource = "-total-ram-gb"
+	} else {
+		ramBytes, source, err := sysinfo.DetectTotalRAM()
+		if err != nil {
+			return nil, err
+		}
+		info.TotalRAMGB = ramBytes / (1024 * 1024 * 1024)
+		info.TotalRAMSource = source
+	}
+	info.Virtualization = detectVirtualization()
+
+	// Hold back RAM for the OS on a sliding scale
+	reserveGB, tier := sysinfo.OSReserveGB(info.TotalRAMGB, limits.ReserveTiers)
+	info.AvailableRAMGB = info.TotalRAMGB - reserveGB
+	info.AvailableRAMNote = fmt.Sprintf("%d GB total - %d GB OS reserve (%.0f%% tier)",
+		info.TotalRAMGB, reserveGB, tier.ReservePercent)
+
+	// On a Mac, open browsers and IDEs can leave far less free than the reserve assumes; the reserve stays
+	// the upper bound. Skipped with -total-ram-gb, which usually describes a different machine.
+	if runtime.GOOS == "darwin" && totalRAMOverrideGB == 0 {
+		if freeGB, ok := readFreeRAMGB(); ok && int64(freeGB) < info.AvailableRAMGB {
+			info.AvailableRAMGB = int64(freeGB)
+			info.AvailableRAMNote = fmt.Sprintf("%.1f GB free now (vm_stat free + inactive + speculative), below the %d GB total - %d GB OS reserve",
+				freeGB, info.TotalRAMGB, reserveGB)
+		}
+	}
+
+	return info, nil
+}
+
+// DMI vendor/product strings of common hypervisors and cloud instances, mapped to a short name
+var dmiHypervisors = []struct{ marker, name string }{
+	{"QEMU", "qemu"},
+	{"KVM", "kvm"},
+	{"VMware", "vmware"},
+	{"VirtualBox", "virtualbox"},
+	{"innotek", "virtualbox"},
+	{"Xen", "xen"},
+	{"Parallels", "parallels"},
+	{"Virtual Machine", "hyper-v"},
+	{"Amazon EC2", "amazon"},
+	{"Google Compute Engine", "google"},
+}
+
+// detectVirtualization reports the hypervisor the host runs under: sysctl kern.hv_vmm_present on macOS,
+// then systemd-detect-virt, DMI strings and the cpuinfo hypervisor flag on Linux. Containers don't count,
+// since they share the host's CPU and memory.
+func detectVirtualization() string {
+	if output, err := exec.Command("sysctl", "-n", "kern.hv_vmm_present").Output(); err == nil {
+		if strings.TrimSpace(string(output)) == "1" {
+			return "macOS virtual machine"
+		}
+		return ""
+	}
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+
+	// Exits non-zero and prints "none" on bare metal
+	if output, err := exec.Command("systemd-detect-virt", "--vm").Output(); err == nil {
+		if virt := strings.TrimSpace(string(output)); virt != "" && virt != "none" {
+			return virt
+		}
+		return ""
+	}
+
+	var dmi []string
+	for _, file := range []string{"/sys/class/dmi/id/sys_vendor", "/sys/class/dmi/id/product_name"} {
+		if data, err := os.ReadFile(file); err == nil {
+			dmi = append(dmi, strings.TrimSpace(string(data)))
+		}
+	}
+	joined := strings.Join(dmi, " ")
+	for _, h := range dmiHypervisors {
+		if strings.Contains(joined, h.marker) {
+			return h.name
+		}
+	}
+
+	if data, err := os.ReadFile("/proc/cpuinfo"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, "flags") && strings.Contains(line+" ", " hypervisor ") {
+				return "unknown hypervisor"
+			}
+		}
+	}
+	return ""
+}
+
+// readFreeRAMGB returns memory that can be used right now: free + inactive + speculative pages from vm_stat
+// on macOS, MemAvailable on Linux
+func readFreeRAMGB() (float64, bool) {
+	switch runtime.GOOS {
+	case "darwin":
+		if output, err := exec.Command("vm_stat").Output(); err == nil {
+			pageSize, pages := parseVMStat(string(output))
+			free := pages["Pages free"] + pages["Pages inactive"] + pages["Pages speculative"]
+			return float64(free*pageSize) / (1024 * 1024 * 1024), true
+		}
+	case "linux":
+		if data, err := os.ReadFile("/proc/meminfo"); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				fields := strings.Fields(line)
+				if len(fields) >= 2 && fields[0] == "MemAvailable:" {
+					if kb, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+						return float64(kb) / (1024 * 1024), true
+					}
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+func captureHostSnapshot() HostSnapshot {
+	snapshot := HostSnapshot{FreeRAMGB: -1, LoadAvg1m: -1}
+	if freeGB, ok := readFreeRAMGB(); ok {
+		snapshot.FreeRAMGB = freeGB
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		// vm.loadavg looks like "{ 2.10 1.95 1.80 }"
+		if output, err := exec.Command("sysctl", "-n", "vm.loadavg").Output(); err == nil {
+			fields := strings.Fields(strings.Trim(strings.TrimSpace(string(output)), "{}"))
+			if len(fields) > 0 {
+				if load, err := strconv.ParseFloat(fields[0], 64); err == nil {
+					snapshot.LoadAvg1m = load
+				}
+			}
+		}
+	case "linux":
+		if data, err := os.ReadFile("/proc/loadavg"); err == nil {
+			fields := strings.Fields(string(data))
+			if len(fields) > 0 {
+				if load, err := strconv.ParseFloat(fields[0], 64); err == nil {
+					snapshot.LoadAvg1m = load
+				}
+			}
+		}
+	}
+
+	return snapshot
+}
+
+// How often -gpu-sample polls; powermetrics takes this long per reading anyway
+const gpuSampleInterval = time.Second
+
+// One GPU reading; -1 for what the source doesn't report
+type gpuReading struct {
+	utilPercent float64
+	tempC       float64
+	powerW      float64
+}
+
+var (
+	gpuSourceOnce sync.Once
+	gpuSource     string // "nvidia-smi", "powermetrics" or "" when no source works
+)
+
+// detectGPUSource picks nvidia-smi when it answers, else powermetrics on macOS when it can run without a password
+// prompt (it needs root). Without either, it warns once and sampling is skipped.
+func detectGPUSource() string {
+	gpuSourceOnce.Do(func() {
+		if _, err := readNvidiaSMI(); err == nil {
+			gpuSource = "nvidia-smi"
+			return
+		}
+		if runtime.GOOS == "darwin" {
+			if _, err := readPowermetrics(); err == nil {
+				gpuSource = "powermetrics"
+				return
+			}
+			fmt.Println("⚠️  -gpu-sample: powermetrics needs root; run with sudo (or allow it in sudoers) to sample the GPU. Continuing without.")
+			return
+		}
+		fmt.Println("⚠️  -gpu-sample: nvidia-smi is not available; continuing without GPU sampling")
+	})
+	return gpuSource
+}
+
+// readNvidiaSMI reads every GPU once and combines them: the busiest GPU's utilization, the hottest temperature and
+// the total power draw
+func readNvidiaSMI() (gpuReading, error) {
+	output, err := exec.Command("nvidia-smi", "--query-gpu=utilization.gpu,temperature.gpu,power.draw",
+		"--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return gpuReading{}, err
+	}
+	reading := gpuReading{utilPercent: -1, tempC: -1, powerW: -1}
+	gpus := 0
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) != 3 {
+			continue
+		}
+		gpus++
+		// A figure the card doesn't report reads "[N/A]" and is left out
+		if util, err := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64); err == nil && util > reading.utilPercent {
+			reading.utilPercent = util
+		}
+		if temp, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64); err == nil && temp > reading.tempC {
+			reading.tempC = temp
+		}
+		if power, err := strconv.ParseFloat(strings.TrimSpace(fields[2]), 64); err == nil {
+			reading.powerW = math.Max(reading.powerW, 0) + power
+		}
+	}
+	if gpus == 0 {
+		return gpuReading{}, errors.New("nvidia-smi listed no GPUs")
+	}
+	return reading, nil
+}
+
+// readPowermetrics takes one gpu_power sample over gpuSampleInterval. It runs sudo -n, which fails at once instead of
+// prompting when no password is cached, unless the tool already runs as root.
+func readPowermetrics() (gpuReading, error) {
+	args := []string{"powermetrics", "--samplers", "gpu_power", "-i", strconv.Itoa(int(gpuSampleInterval.Milliseconds())), "-n", "1"}
+	if os.Geteuid() != 0 {
+		args = append([]string{"sudo", "-n"}, args...)
+	}
+	output, err := exec.Command(args[0], args[1:]...).Output()
+	if err != nil {
+		return gpuReading{}, err
+	}
+	return parsePowermetrics(string(output))
+}
+
+// parsePowermetrics reads "GPU HW active residency:  45.12% (...)" and "GPU Power: 1234 mW"
+func parsePowermetrics(output string) (gpuReading, error) {
+	reading := gpuReading{utilPercent: -1, tempC: -1, powerW: -1}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		value := ""
+		if sep := strings.Index(line, ":"); sep > 0 {
+			if fields := strings.Fields(line[sep+1:]); len(fields) > 0 {
+				value = fields[0]
+			}
+		}
+		switch {
+		case strings.HasPrefix(line, "GPU HW active residency:"), strings.HasPrefix(line, "GPU active residency:"):
+			if util, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64); err == nil {
+				reading.utilPercent = util
+			}
+		case strings.HasPrefix(line, "GPU Power:"):
+			if mw, err := strconv.ParseFloat(value, 64); err == nil {
+				reading.powerW = mw / 1000
+			}
+		}
+	}
+	if reading.utilPercent < 0 && reading.powerW < 0 {
+		return gpuReading{}, errors.New("no GPU figures in powermetrics output")
+	}
+	return reading, nil
+}
+
+// startGPUSampler polls the GPU from now until the returned function is called, which returns the averages and
+// peaks, or nil if no source works or no reading completed
+func startGPUSampler() func() *GPUStats {
+	source := detectGPUSource()
+	if source == "" {
+		return func() *GPUStats { return nil }
+	}
+	read := readNvidiaSMI
+	if source == "powermetrics" {
+		read = readPowermetrics
+	}
+
+	var mu sync.Mutex
+	var readings []gpuReading
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			if reading, err := read(); err == nil {
+				mu.Lock()
+				readings = append(readings, reading)
+				mu.Unlock()
+			}
+			// powermetrics already spent the interval taking its sample
+			wait := gpuSampleInterval
+			if source == "powermetrics" {
+				wait = 0
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+
+	return func() *GPUStats {
+		close(done)
+		// Don't hold up the next test for a reading still in flight; what arrived so far is enough
+		select {
+		case <-finished:
+		case <-time.After(gpuSampleInterval / 10):
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return summarizeGPUReadings(source, readings)
+	}
+}
+
+// summarizeGPUReadings averages and takes the peak of each figure; a figure no reading reported stays -1
+func summarizeGPUReadings(source string, readings []gpuReading) *GPUStats {
+	if len(readings) == 0 {
+		return nil
+	}
+	stats := &GPUStats{Source: source, Samples: len(readings)}
+	summarize := func(value func(gpuReading) float64) (float64, float64) {
+		sum, peak, n := 0.0, -1.0, 0
+		for _, r := range readings {
+			if v := value(r); v >= 0 {
+				sum += v
+				n++
+				peak = math.Max(peak, v)
+			}
+		}
+		if n == 0 {
+			return -1, -1
+		}
+		return sum / float64(n), peak
+	}
+	stats.AvgUtilPercent, stats.PeakUtilPercent = summarize(func(r gpuReading) float64 { return r.utilPercent })
+	stats.AvgTempC, stats.PeakTempC = summarize(func(r gpuReading) float64 { return r.tempC })
+	stats.AvgPowerW, stats.PeakPowerW = summarize(func(r gpuReading) float64 { return r.powerW })
+	return stats
+}
+
+// peakGPUStats combines the GPU readings of a model's tests into its peaks; nil if none were sampled
+func peakGPUStats(results []BenchmarkResult) *GPUStats {
+	var peak *GPUStats
+	for _, r := range results {
+		if r.GPU == nil {
+			continue
+		}
+		if peak == nil {
+			peak = &GPUStats{Source: r.GPU.Source, PeakUtilPercent: -1, PeakTempC: -1, PeakPowerW: -1}
+		}
+		peak.Samples += r.GPU.Samples
+		peak.PeakUtilPercent = math.Max(peak.PeakUtilPercent, r.GPU.PeakUtilPercent)
+		peak.PeakTempC = math.Max(peak.PeakTempC, r.GPU.PeakTempC)
+		peak.PeakPowerW = math.Max(peak.PeakPowerW, r.GPU.PeakPowerW)
+	}
+	return peak
+}
+
+// formatGPUPeaks renders the peaks that were measured, e.g. "98% util, 76°C, 310.5 W"
+func formatGPUPeaks(stats *GPUStats) string {
+	var parts []string
+	if stats.PeakUtilPercent >= 0 {
+		parts = append(parts, fmt.Sprintf("%.0f%% util", stats.PeakUtilPercent))
+	}
+	if stats.PeakTempC >= 0 {
+		parts = append(parts, fmt.Sprintf("%.0f°C", stats.PeakTempC))
+	}
+	if stats.PeakPowerW >= 0 {
+		parts = append(parts, fmt.Sprintf("%.1f W", stats.PeakPowerW))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// readSwapCounters returns the cumulative pages swapped in and out since boot
+func readSwapCounters() (int64, int64, bool) {
+	switch runtime.GOOS {
+	case "darwin":
+		output, err := exec.Command("vm_stat").Output()
+		if err != nil {
+			return 0, 0, false
+		}
+		_, pages := parseVMStat(string(output))
+		return pages["Swapins"], pages["Swapouts"], true
+	case "linux":
+		data, err := os.ReadFile("/proc/vmstat")
+		if err != nil {
+			return 0, 0, false
+		}
+		var swapIns, swapOuts int64
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				continue
+			}
+			value, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			switch fields[0] {
+			case "pswpin":
+				swapIns = value
+			case "pswpout":
+				swapOuts = value
+			}
+		}
+		return swapIns, swapOuts, true
+	}
+	return 0, 0, false
+}
+
+// parseVMStat parses macOS vm_stat output into its page size and a map of page counts by label
+func parseVMStat(output string) (int64, map[string]int64) {
+	pageSize := int64(4096)
+	pages := make(map[string]int64)
+
+	for _, line := range strings.Split(output, "\n") {
+		// Header: "Mach Virtual Memory Statistics: (page size of 16384 bytes)"
+		if strings.Contains(line, "page size of") {
+			fields := strings.Fields(line[strings.Index(line, "page size of"):])
+			if len(fields) >= 4 {
+				if size, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+					pageSize = size
+				}
+			}
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.TrimSuffix(strings.TrimSpace(parts[1]), ".")
+		if count, err := strconv.ParseInt(value, 10, 64); err == nil {
+			pages[strings.TrimSpace(parts[0])] = count
+		}
+	}
+
+	return pageSize, pages
+}
+
+// waitForOllama polls the health check for up to seconds and reports whether the server came back
+func waitForOllama(host string, seconds int) bool {
+	if seconds <= 0 {
+		return false
+	}
+	fmt.Printf("   Waiting up to %ds for it to come back (server_recovery_wait_seconds)...\n", seconds)
+	deadline := time.Now().Add(time.Duration(seconds) * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(2 * time.Second)
+		if _, err := ollama.CheckHealth(host); err == nil {
+			return true
+		}
+	}
+	fmt.Printf("   Ollama did not come back within %ds\n", seconds)
+	return false
+}
+
+func checkOllamaRunning(host string) bool {
+	_, err := ollama.CheckHealth(host)
+	return err == nil
+}
+
+// stringList collects a repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// hostList collects repeated -host flags
+type hostList []string
+
+func (h *hostList) String() string {
+	return strings.Join(*h, ",")
+}
+
+func (h *hostList) Set(value string) error {
+	host, err := ollama.ParseHost(value)
+	if err != nil {
+		return err
+	}
+	*h = append(*h, host)
+	return nil
+}
+
+// isLocalHost reports whether the endpoint runs on this machine, where host snapshots are meaningful
+func isLocalHost(host string) bool {
+	u, err := url.Parse(host)
+	if err != nil {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+// Installed-model metadata per endpoint, fetched from /api/tags once per run so that discovery, dedup,
+// RAM sizing and sorting all see the same facts. A successful pull invalidates the endpoint's entry.
+// Each entry fetches on its own, so different endpoints can be fetched concurrently.
+var (
+	modelCacheMu sync.Mutex
+	modelCache   = map[string]*modelCacheEntry{}
+)
+
+type modelCacheEntry struct {
+	once   sync.Once
+	models []ollama.Model
+	err    error
+}
+
+// getInstalledModels returns a copy of the cached model list for host, fetching it on first use
+func getInstalledModels(host string) ([]ollama.Model, error) {
+	modelCacheMu.Lock()
+	entry, ok := modelCache[host]
+	if !ok {
+		entry = &modelCacheEntry{}
+		modelCache[host] = entry
+	}
+	modelCacheMu.Unlock()
+
+	entry.once.Do(func() {
+		entry.models, entry.err = ollama.ListModels(host)
+	})
+	if entry.err != nil {
+		// Don't cache failures; the next caller tries again
+		modelCacheMu.Lock()
+		if modelCache[host] == entry {
+			delete(modelCache, host)
+		}
+		modelCacheMu.Unlock()
+		return nil, entry.err
+	}
+	// Callers sort and filter the list, which must not reorder the cache
+	return append([]ollama.Model(nil), entry.models...), nil
+}
+
+// Per-model /api/show answers, cached like the model list: one entry per endpoint and model, dropped with it on pull
+var (
+	showCacheMu sync.Mutex
+	showCache   = map[showCacheKey]*showCacheEntry{}
+)
+
+type showCacheKey struct {
+	host  string
+	model string
+}
+
+type showCacheEntry struct {
+	once      sync.Once
+	modelInfo map[string]interface{}
+	err       error
+}
+
+// getModelInfo returns the model_info map /api/show reports for model, fetching it on first use
+func getModelInfo(host string, model string) (map[string]interface{}, error) {
+	key := showCacheKey{host, model}
+	showCacheMu.Lock()
+	entry, ok := showCache[key]
+	if !ok {
+		entry = &showCacheEntry{}
+		showCache[key] = entry
+	}
+	showCacheMu.Unlock()
+
+	entry.once.Do(func() {
+		entry.modelInfo, entry.err = fetchModelInfo(host, model)
+	})
+	if entry.err != nil {
+		showCacheMu.Lock()
+		if showCache[key] == entry {
+			delete(showCache, key)
+		}
+		showCacheMu.Unlock()
+		return nil, entry.err
+	}
+	return entry.modelInfo, nil
+}
+
+func fetchModelInfo(host string, model string) (map[string]interface{}, error) {
+	body, err := json.Marshal(map[string]string{"model": model})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ollama.MetadataClient.Post(host+"/api/show", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("/api/show %s: HTTP %d", model, resp.StatusCode)
+	}
+
+	var showResp struct {
+		ModelInfo map[string]interface{} `json:"model_info"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&showResp); err != nil {
+		return nil, err
+	}
+	return showResp.ModelInfo, nil
+}
+
+// forEachBounded calls fn for 0..n-1 on at most workers goroutines and returns when all calls are done.
+// Callers store results by index so output order never depends on completion order.
+func forEachBounded(n int, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// invalidateModelCache drops host's cached model list, e.g. after a pull changed it
+func invalidateModelCache(host string) {
+	modelCacheMu.Lock()
+	delete(modelCache, host)
+	modelCacheMu.Unlock()
+
+	showCacheMu.Lock()
+	defer showCacheMu.Unlock()
+	for key := range showCache {
+		if key.host == host {
+			delete(showCache, key)
+		}
+	}
+}
+
+// installedModel looks up one installed model's metadata by name
+func installedModel(host string, name string) (ollama.Model, bool) {
+	installed, err := getInstalledModels(host)
+	if err != nil {
+		return ollama.Model{}, false
+	}
+	for _, m := range installed {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return ollama.Model{}, false
+}
+
+// installedSizes maps installed model names to their size in bytes; empty if Ollama can't be queried
+func installedSizes(host string) map[string]int64 {
+	sizes := make(map[string]int64)
+	if installed, err := getInstalledModels(host); err == nil {
+		for _, m := range installed {
+			sizes[m.Name] = m.Size
+		}
+	}
+	return sizes
+}
+
+// A model resident in Ollama's memory, as listed by /api/ps
+type LoadedModel struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`       // bytes in memory: weights, KV cache and buffers
+	SizeVRAM  int64     `json:"size_vram"`  // part of Size held in GPU memory
+	ExpiresAt time.Time `json:"expires_at"` // when keep_alive unloads it
+}
+
+// getLoadedModels returns the models currently loaded on host
+func getLoadedModels(host string) ([]LoadedModel, error) {
+	resp, err := ollama.MetadataClient.Get(host + "/api/ps")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var psResp struct {
+		Models []LoadedModel `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&psResp); err != nil {
+		return nil, err
+	}
+	return psResp.Models, nil
+}
+
+// findLoadedModel returns model's /api/ps entry, false if it isn't loaded or the list can't be read
+func findLoadedModel(host string, model string) (LoadedModel, bool) {
+	loaded, err := getLoadedModels(host)
+	if err != nil {
+		return LoadedModel{}, false
+	}
+	for _, m := range loaded {
+		if m.Name == model {
+			return m, true
+		}
+	}
+	return LoadedModel{}, false
+}
+
+// getLoadedModelSize returns the bytes a loaded model occupies (weights, KV cache and buffers) according to /api/ps
+func getLoadedModelSize(host string, model string) (int64, error) {
+	loaded, err := getLoadedModels(host)
+	if err != nil {
+		return 0, err
+	}
+	for _, m := range loaded {
+		if m.Name == model {
+			return m.Size, nil
+		}
+	}
+	return 0, fmt.Errorf("%s is not loaded", model)
+}
+
+func getOllamaLibraryModels(host string, config *Config) []string {
+	var models []string
+
+	// Get installed models
+	installed, err := getInstalledModels(host)
+	if err != nil {
+		return models
+	}
+
+	installedModels := make(map[string]bool)
+	for _, m := range installed {
+		installedModels[m.Name] = true
+	}
+
+	// For each enabled family, find all variants
+	for _, family := range config.LLMFamilies {
+		if !family.Enabled {
+			continue
+		}
+
+		// Check installed models for this family
+		installedMatches := 0
+		for modelName := range installedModels {
+			if strings.HasPrefix(modelName, family.Name) {
+				models = append(models, modelName)
+				installedMatches++
+			}
+		}
+
+		// Warn about families that match nothing installed and aren't known to the registry
+		if installedMatches == 0 && !isKnownFamily(family.Name) {
+			fmt.Printf("⚠️  Warning: enabled family %q matches no installed models and is not a known family\n", family.Name)
+			if suggestion := suggestFamilyName(family.Name, installedModels); suggestion != "" {
+				fmt.Printf("   Did you mean %q?\n", suggestion)
+			}
+		}
+
+		// test_all_variants adds the family's published sizes, from the registry or the built-in list
+		if family.TestAllVariants {
+			commonVariants := getCommonVariants(family.Name)
+			for _, variant := range commonVariants {
+				// Only add if not already in list
+				found := false
+				for _, m := range models {
+					if m == variant {
+						found = true
+						break
+					}
+				}
+				if !found {
+					models = append(models, variant)
+				}
+			}
+		}
+	}
+
+	// Models imported from GGUF files rarely share a family prefix; test them whenever they're installed
+	for model := range config.GGUFFiles {
+		found := false
+		for _, m := range models {
+			if m == model {
+				found = true
+				break
+			}
+		}
+		if installedModels[model] && !found {
+			models = append(models, model)
+		}
+	}
+
+	// Sort models
+	sort.Strings(models)
+
+	return models
+}
+
+// Common variants per family, used when test_all_variants is set
+var commonVariants = map[string][]string{
+	"qwen2.5":        {"qwen2.5:0.5b", "qwen2.5:1.5b", "qwen2.5:3b", "qwen2.5:7b", "qwen2.5:14b", "qwen2.5:32b"},
+	"gemma2":         {"gemma2:2b", "gemma2:9b", "gemma2:27b"},
+	"llama3.2":       {"llama3.2:1b", "llama3.2:3b"},
+	"llama3.1":       {"llama3.1:8b", "llama3.1:70b", "llama3.1:405b"},
+	"mistral":        {"mistral:7b", "mistral:latest"},
+	"codellama":      {"codellama:7b", "codellama:13b", "codellama:34b", "codellama:70b"},
+	"phi3":           {"phi3:mini", "phi3:medium"},
+	"deepseek-coder": {"deepseek-coder:1.3b", "deepseek-coder:6.7b", "deepseek-coder:33b"},
+}
+
+// getCommonVariants returns the family's size tags from the Ollama registry, falling back to the built-in
+// commonVariants list (or family:latest) when the registry can't be reached
+func getCommonVariants(family string) []string {
+	if v, err := getRegistryVariants(family); err == nil && len(v) > 0 {
+		return v
+	}
+	if v, ok := commonVariants[family]; ok {
+		return v
+	}
+	return []string{family + ":latest"}
+}
+
+// isKnownFamily reports whether the registry or the built-in list has variants for family
+func isKnownFamily(family string) bool {
+	if _, ok := commonVariants[family]; ok {
+		return true
+	}
+	v, err := getRegistryVariants(family)
+	return err == nil && len(v) > 0
+}
+
+// Tag list of a library model on the Ollama registry (the Docker registry v2 API)
+const (
+	registryTagsURL = "https://registry.ollama.ai/v2/library/%s/tags/list"
+	registryTimeout = 5 * time.Second
+)
+
+// Registry lookups per family, failures included, so an offline run pays for each family once
+var (
+	registryCacheMu sync.Mutex
+	registryCache   = map[string]*registryCacheEntry{}
+)
+
+type registryCacheEntry struct {
+	once     sync.Once
+	variants []string
+	err      error
+}
+
+// getRegistryVariants returns the cached registry variants of family, fetching them on first use
+func getRegistryVariants(family string) ([]string, error) {
+	registryCacheMu.Lock()
+	entry, ok := registryCache[family]
+	if !ok {
+		entry = &registryCacheEntry{}
+		registryCache[family] = entry
+	}
+	registryCacheMu.Unlock()
+
+	entry.once.Do(func() {
+		entry.variants, entry.err = fetchRegistryVariants(family)
+		if entry.err != nil {
+			progressf("  Registry lookup for %s failed (%v); using the built-in variant list\n", family, entry.err)
+		}
+	})
+	return entry.variants, entry.err
+}
+
+// fetchRegistryVariants lists family's size tags ("7b", "mini", ...) as family:tag. Quantization and
+// fine-tune tags (anything with a "-") and "latest", an alias of one of the sizes, are left out.
+func fetchRegistryVariants(family string) ([]string, error) {
+	client := &http.Client{Timeout: registryTimeout}
+	resp, err := client.Get(fmt.Sprintf(registryTagsURL, url.PathEscape(family)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry returned status %d", resp.StatusCode)
+	}
+
+	var list struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, tag := range list.Tags {
+		if tag != "latest" && !strings.Contains(tag, "-") {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 && len(list.Tags) > 0 {
+		tags = []string{"latest"}
+	}
+	// Smallest first, like the built-in lists; named sizes such as "mini" sort by name after them
+	sort.Slice(tags, func(i, j int) bool {
+		pi, pj := parseParameterCount(tags[i]), parseParameterCount(tags[j])
+		switch {
+		case pi == pj:
+			return tags[i] < tags[j]
+		case pi == 0 || pj == 0:
+			return pj == 0
+		}
+		return pi < pj
+	})
+	variants := make([]string, len(tags))
+	for i, tag := range tags {
+		variants[i] = family + ":" + tag
+	}
+	return variants, nil
+}
+
+// suggestFamilyName returns the known or installed family closest to name, or "" if nothing is close
+func suggestFamilyName(name string, installedModels map[string]bool) string {
+	candidates := make(map[string]bool)
+	for family := range commonVariants {
+		candidates[family] = true
+	}
+	for modelName := range installedModels {
+		candidates[strings.Split(modelName, ":")[0]] = true
+	}
+
+	best := ""
+	bestDistance := 0
+	for candidate := range candidates {
+		distance := editDistance(name, candidate)
+		if best == "" || distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	// Only suggest names that are plausibly a typo
+	maxDistance := len(name) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	if best == "" || bestDistance > maxDistance {
+		return ""
+	}
+	return best
+}
+
+// editDistance computes the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j] + 1
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+			if prev[j-1]+cost < curr[j] {
+				curr[j] = prev[j-1] + cost
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func extractModelSize(modelName string) string {
+	if info, ok := ggufModels[modelName]; ok && info.ParameterCount > 0 {
+		return gguf.FormatParameterCount(info.ParameterCount)
+	}
+	parts := strings.Split(modelName, ":")
+	if len(parts) > 1 {
+		return parts[1]
+	}
+	return "unknown"
+}
+
+// Q4 RAM in GB by the parameter token of a tag (~0.5-0.6 GB per billion parameters, plus overhead)
+var q4RAMBySize = map[string]int64{
+	"0.5b": 1, "0.6b": 1, "1b": 2, "1.3b": 2, "1.5b": 2, "1.7b": 2, "2b": 2, "3b": 3, "6.7b": 5, "7b": 5,
+	"8b": 6, "9b": 6, "13b": 9, "14b": 9, "27b": 16, "32b": 20, "33b": 20, "34b": 20, "70b": 40,
+	"235b": 130, "405b": 220, "671b": 370, "mini": 3, "medium": 9,
+}
+
+// RAM multipliers over Q4 for quantizations named in a tag (e.g. "32b-instruct-q5_K_M"); untagged means Q4
+var quantRAMFactors = []struct {
+	marker string
+	factor float64
+}{
+	{"fp16", 2.0},
+	{"f16", 2.0},
+	{"q8", 1.5},
+	{"q6", 1.35},
+	{"q5", 1.2},
+	{"q4", 1.0},
+	{"q3", 0.8},
+	{"q2", 0.6},
+}
+
+// estimateModelRAMDetail returns the Q4 estimate for a tag's parameter count and the quantization
+// multiplier applied on top of it
+func estimateModelRAMDetail(modelName string) (int64, string, float64) {
+	size := strings.ToLower(extractModelSize(modelName))
+	token := strings.SplitN(size, "-", 2)[0]
+
+	// Match the whole parameter token: "32b" must not be read as "2b"
+	q4GB, ok := q4RAMBySize[token]
+	if !ok {
+		q4GB = 5 // default
+		if billions := parseParameterCount(token); billions > 0 {
+			q4GB = int64(math.Ceil(billions * 0.6))
+		}
+	}
+
+	for _, q := range quantRAMFactors {
+		if strings.Contains(size, q.marker) {
+			return q4GB, q.marker, q.factor
+		}
+	}
+	return q4GB, "q4", 1.0
+}
+
+// estimateModelRAM is the name-based RAM guess: Q4 size for the parameter count, scaled for Q2 (-40%), Q3 (-20%),
+// Q5 (+20%), Q6 (+35%), Q8 (+50%) and F16 (x2)
+func estimateModelRAM(modelName string) int64 {
+	q4GB, _, factor := estimateModelRAMDetail(modelName)
+	return int64(math.Ceil(float64(q4GB) * factor))
+}
+
+// applyModelFilters keeps models matching any include pattern (all if none are set),
+// then drops those matching an exclude pattern. Patterns use glob syntax, e.g. "qwen2.5:32b" or "*:405b".
+func applyModelFilters(models []string, config *Config) ([]string, int) {
+	if len(config.Include) == 0 && len(config.Exclude) == 0 {
+		return models, 0
+	}
+
+	var kept []string
+	for _, model := range models {
+		if len(config.Include) > 0 && !matchesAnyPattern(model, config.Include) {
+			continue
+		}
+		if matchesAnyPattern(model, config.Exclude) {
+			continue
+		}
+		kept = append(kept, model)
+	}
+	return kept, len(models) - len(kept)
+}
+
+func matchesAnyPattern(model string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, model); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeModels collapses installed tags with identical digests (e.g. llama3.1:8b and llama3.1:latest),
+// keeping the first tag in order and returning the others as its aliases
+func dedupeModels(host string, models []string) ([]string, map[string][]string) {
+	aliases := make(map[string][]string)
+
+	installed, err := getInstalledModels(host)
+	if err != nil {
+		return models, aliases
+	}
+	digests := make(map[string]string)
+	for _, m := range installed {
+		digests[m.Name] = m.Digest
+	}
+
+	var unique []string
+	canonical := make(map[string]string) // digest -> first model name
+	for _, model := range models {
+		digest := digests[model]
+		if digest == "" {
+			unique = append(unique, model)
+			continue
+		}
+		if first, ok := canonical[digest]; ok {
+			aliases[first] = append(aliases[first], model)
+			continue
+		}
+		canonical[digest] = model
+		unique = append(unique, model)
+	}
+	return unique, aliases
+}
+
+func filterInstalledModels(host string, models []string) ([]string, int) {
+	installed, err := getInstalledModels(host)
+	if err != nil {
+		return nil, len(models)
+	}
+
+	installedModels := make(map[string]bool)
+	for _, m := range installed {
+		installedModels[m.Name] = true
+	}
+
+	var kept []string
+	for _, model := range models {
+		if installedModels[model] {
+			kept = append(kept, model)
+		}
+	}
+	return kept, len(models) - len(kept)
+}
+
+// modelRAMRequirement returns the RAM needed for a model in GB and whether it came from its GGUF
+// file ("gguf"), the installed size reported by Ollama ("measured") or the name-based heuristic ("estimated")
+func modelRAMRequirement(model string, installedSizes map[string]int64) (int64, string) {
+	if info, ok := ggufModels[model]; ok {
+		return gguf.RAMGB(info), "gguf"
+	}
+	if size, ok := installedSizes[model]; ok && size > 0 {
+		sizeGB := float64(size) / (1024 * 1024 * 1024)
+		return int64(math.Ceil(sizeGB * gguf.RuntimeOverheadFactor)), "measured"
+	}
+	return estimateModelRAM(model), "estimated"
+}
+
+// parseParameterCount converts sizes like "8.0B", "494.03M", "1.5b" or "70b" to billions of parameters, 0 if unparseable
+func parseParameterCount(size string) float64 {
+	size = strings.ToUpper(strings.TrimSpace(size))
+	multipliers := map[string]float64{"K": 1e-6, "M": 1e-3, "B": 1, "T": 1e3}
+	if len(size) < 2 {
+		return 0
+	}
+	multiplier, ok := multipliers[size[len(size)-1:]]
+	if !ok {
+		return 0
+	}
+	value, err := strconv.ParseFloat(size[:len(size)-1], 64)
+	if err != nil {
+		return 0
+	}
+	return value * multiplier
+}
+
+// modelParamsBillions prefers the parameter count Ollama reports for an installed model,
+// then the GGUF header or the size in the tag (e.g. "qwen2.5:1.5b")
+func modelParamsBillions(host string, model string) float64 {
+	if m, ok := installedModel(host, model); ok {
+		if params := parseParameterCount(m.Details.ParameterSize); params > 0 {
+			return params
+		}
+	}
+	return parseParameterCount(extractModelSize(model))
+}
+
+// filterModelsByResources returns the models that fit the resource limits, and for each one left out the
+// constraint that excluded it
+func filterModelsByResources(host string, models []string, sysInfo *SystemInfo, config *Config, explain bool) ([]string, map[string]string) {
+	skipped := make(map[string]string)
+	if !config.TestSettings.SkipIfInsufficientResources {
+		if explain {
+			fmt.Println("\nRAM math: skip_if_insufficient_resources is false, every model is tested")
+		}
+		return models, skipped
+	}
+
+	// Installed models have a known size; only fall back to the name heuristic for the rest
+	sizes := installedSizes(host)
+
+	if explain {
+		fmt.Printf("\nRAM math (available: %d GB = %s%s):\n", sysInfo.AvailableRAMGB, sysInfo.AvailableRAMNote,
+			ramCapNote(sysInfo, config.ResourceLimits))
+	}
+
+	var testable []string
+
+	for _, model := range models {
+		estimatedRAM, source := modelRAMRequirement(model, sizes)
+		minFree := config.ResourceLimits.MinFreeRAMGB.WholeGB()
+		reason := resourceShortfall(estimatedRAM, sysInfo, config.ResourceLimits)
+
+		if explain {
+			q4GB, quant, factor := estimateModelRAMDetail(model)
+			multiplier := fmt.Sprintf("Q4 %d GB x %.2f for %s", q4GB, factor, strings.ToUpper(quant))
+			switch source {
+			case "measured":
+				multiplier = fmt.Sprintf("installed size x%.1f overhead", gguf.RuntimeOverheadFactor)
+			case "gguf":
+				multiplier = fmt.Sprintf("%s GGUF file size x%.1f overhead", ggufModels[model].Quantization, gguf.RuntimeOverheadFactor)
+			}
+			verdict := "fits"
+			if reason != "" {
+				verdict = "does not fit: " + reason
+			}
+			fmt.Printf("  %-25s %d GB (%s, %s) + %d GB reserve = %d GB vs %d GB available → %s\n",
+				model, estimatedRAM, source, multiplier, minFree, estimatedRAM+minFree, sysInfo.AvailableRAMGB, verdict)
+		}
+
+		if reason == "" {
+			testable = append(testable, model)
+		} else {
+			skipped[model] = reason
+		}
+	}
+
+	return testable, skipped
+}
+
+// Largest share of total RAM one model may need when the config has no max_ram_usage_percent
+const defaultMaxRAMUsagePercent = 70
+
+// ramUsageCapGB is max_ram_usage_percent of total RAM in GB
+func ramUsageCapGB(sysInfo *SystemInfo, limits ResourceLimits) float64 {
+	return float64(sysInfo.TotalRAMGB) * float64(limits.MaxRAMUsagePercent) / 100
+}
+
+// ramCapNote describes the max_ram_usage_percent cap for messages
+func ramCapNote(sysInfo *SystemInfo, limits ResourceLimits) string {
+	return fmt.Sprintf(", at most %.1f GB per model = %d%% of %d GB", ramUsageCapGB(sysInfo, limits),
+		limits.MaxRAMUsagePercent, sysInfo.TotalRAMGB)
+}
+
+// resourceShortfall says which resource limit a model needing ramGB breaks, or returns "" when it fits: it must
+// stay within max_ram_usage_percent of total RAM and leave min_free_ram_gb of the available RAM free
+func resourceShortfall(ramGB int64, sysInfo *SystemInfo, limits ResourceLimits) string {
+	if capGB := ramUsageCapGB(sysInfo, limits); float64(ramGB) > capGB {
+		return fmt.Sprintf("needs ~%d GB, over the max_ram_usage_percent cap of %.1f GB (%d%% of %d GB)",
+			ramGB, capGB, limits.MaxRAMUsagePercent, sysInfo.TotalRAMGB)
+	}
+	minFree := limits.MinFreeRAMGB.WholeGB()
+	if ramGB+minFree > sysInfo.AvailableRAMGB {
+		return fmt.Sprintf("needs ~%d GB + %d GB min_free_ram_gb = %d GB, more than the %d GB available",
+			ramGB, minFree, ramGB+minFree, sysInfo.AvailableRAMGB)
+	}
+	return ""
+}
+
+// printDryRun lists, per endpoint, each model the run would test with its estimated RAM and whether it has to be
+// pulled first, and totals the downloads. A pull's size is estimated as the RAM estimate without runtime overhead.
+func printDryRun(hosts []string, modelsFor func(host string) []string, sysInfo *SystemInfo, config *Config) {
+	fmt.Printf("\nDry run: nothing is pulled or generated (%d GB available for models on this machine)\n", sysInfo.AvailableRAMGB)
+	for _, host := range hosts {
+		models := modelsFor(host)
+		if isLocalHost(host) {
+			fmt.Printf("\n%s\n", host)
+		} else {
+			fmt.Printf("\n%s (remote: resource limits not applied)\n", host)
+		}
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		sizes := installedSizes(host)
+		var pulls int
+		var downloadGB float64
+		for _, model := range models {
+			ramGB, source := modelRAMRequirement(model, sizes)
+			action := "installed"
+			if _, ok := sizes[model]; !ok {
+				if config.TestSettings.AutoPullModels {
+					pullGB := float64(ramGB) / gguf.RuntimeOverheadFactor
+					action = fmt.Sprintf("pull, ~%.1f GB download", pullGB)
+					pulls++
+					downloadGB += pullGB
+				} else {
+					action = "not installed, skipped (auto_pull_models is off)"
+				}
+			}
+			kind := ""
+			if isEmbeddingModel(model) {
+				kind = " (embedding)"
+			}
+			fmt.Printf("  %-25s ~%3d GB RAM %-11s | %s%s\n", model, ramGB, "("+source+")", action, kind)
+		}
+		fmt.Printf("%d models to test, %d to pull (~%.1f GB of downloads)\n", len(models), pulls, downloadGB)
+	}
+}
+
+// parallelWorkers sizes the parallel_testing pool so that even the largest models, all loaded at once, fit in
+// the RAM left after the min_free_ram_gb reserve and within max_ram_usage_percent. It is never below 1 or above the
+// number of models.
+func parallelWorkers(host string, models []string, sysInfo *SystemInfo, config *Config) int {
+	sizes := installedSizes(host)
+	var largest int64
+	for _, model := range models {
+		if ramGB, _ := modelRAMRequirement(model, sizes); ramGB > largest {
+			largest = ramGB
+		}
+	}
+	if largest < 1 {
+		largest = 1
+	}
+
+	budgetGB := float64(sysInfo.AvailableRAMGB - config.ResourceLimits.MinFreeRAMGB.WholeGB())
+	if capGB := ramUsageCapGB(sysInfo, config.ResourceLimits); capGB < budgetGB {
+		budgetGB = capGB
+	}
+	workers := int(budgetGB / float64(largest))
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(models) {
+		workers = len(models)
+	}
+	return workers
+}
+
+// benchmarkModelsParallel runs models on host with up to workers of them at once. Summaries keep the order of
+// models. Once a model finds Ollama stopped, models that haven't started are reported as not run.
+func benchmarkModelsParallel(host string, models []string, workers int, run func(model string) ModelSummary) []ModelSummary {
+	summaries := make([]ModelSummary, len(models))
+	var mu sync.Mutex
+	stopped := false
+	finished := 0
+
+	parallelProgress = true
+	defer func() { parallelProgress = false }()
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if !quiet {
+					mu.Lock()
+					fmt.Printf("  ▶ %s started\n", models[i])
+					mu.Unlock()
+				}
+				summary := run(models[i])
+				summary.Parallel = true
+				mu.Lock()
+				summaries[i] = summary
+				if summary.ServerStopped {
+					stopped = true
+				}
+				finished++
+				if !quiet {
+					printParallelModelReport(summary, finished, len(models))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	next := 0
+	for ; next < len(models); next++ {
+		mu.Lock()
+		stop := stopped
+		mu.Unlock()
+		if stop {
+			break
+		}
+		jobs <- next
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Report what was gathered instead of grinding through requests that can only fail
+	if notRun := models[next:]; len(notRun) > 0 {
+		fmt.Printf("Stopping the run on %s; reporting the results gathered so far\n", host)
+		fmt.Printf("Not run: %s\n", strings.Join(notRun, ", "))
+		for i := next; i < len(models); i++ {
+			summaries[i] = ModelSummary{
+				ModelName:  models[i],
+				OllamaHost: host,
+				SkipReason: "Not run: Ollama stopped during the benchmark",
+			}
+		}
+	}
+	return summaries
+}
+
+// printParallelModelReport is a parallel model's progress, printed in one piece once it has finished
+func printParallelModelReport(s ModelSummary, finished, total int) {
+	fmt.Printf("\n━ %s (%d/%d finished): passed %d/%d", s.ModelName, finished, total, s.PassedTests, len(s.TestResults))
+	if s.CanRun {
+		fmt.Printf(", %s t/s", formatMetric(s.AvgTokensPerSec))
+	} else if s.SkipReason != "" {
+		fmt.Printf(", %s", s.SkipReason)
+	}
+	fmt.Println()
+	for _, r := range s.TestResults {
+		if r.Success {
+			fmt.Printf("    ✓ %s: %s t/s | %s ms | %d tokens\n", r.TestName, formatMetric(r.TokensPerSecond),
+				formatMetric(r.TotalTimeMs), r.TotalTokens)
+		} else {
+			fmt.Printf("    ✗ %s: %s\n", r.TestName, r.Error)
+		}
+	}
+}
+
+func checkModelInstalled(host string, model string) bool {
+	_, ok := installedModel(host, model)
+	return ok
+}
+
+// pullModel downloads model with a progress bar (hidden by -quiet) and refreshes the endpoint's model list
+func pullModel(host string, model string) error {
+	if err := ollama.Pull(host, model, !quiet && !parallelProgress); err != nil {
+		return err
+	}
+	invalidateModelCache(host)
+	return nil
+}
+
+// Negative keep_alive keeps a model loaded indefinitely
+const keepAliveWarm = "-1m"
+
+// setKeepAlive loads or unloads a model without generating: a prompt-less generate request
+// applies keep_alive, so keepAliveWarm pins the model in memory and "0" evicts it immediately
+func setKeepAlive(host string, model string, keepAlive string) error {
+	return loadModel(host, model, keepAlive, 0)
+}
+
+// loadModel sends a prompt-less generate request, which returns once the model is in memory. A non-zero
+// timeout abandons the load after that long; closing the request makes Ollama cancel it.
+func loadModel(host string, model string, keepAlive string, timeout time.Duration) error {
+	jsonData, err := json.Marshal(ollama.GenerateRequest{Model: model, KeepAlive: keepAlive})
+	if err != nil {
+		return err
+	}
+	client := ollama.NewClient(timeout)
+	timedOut := func(err error) error {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return fmt.Errorf("failed to load within %ds — likely too large", int(timeout.Seconds()))
+		}
+		return err
+	}
+	resp, err := client.Post(host+"/api/generate", "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return timedOut(err)
+	}
+	defer resp.Body.Close()
+
+	var genResp ollama.GenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&genResp); err != nil {
+		return timedOut(err)
+	}
+	if genResp.Error != "" {
+		return errors.New(genResp.Error)
+	}
+	return nil
+}
+
+func runBenchmark(host string, model string, test TestCase, options map[string]interface{}) BenchmarkResult {
+	result := BenchmarkResult{
+		ModelName: model,
+		ModelSize: extractModelSize(model),
+		TestName:  test.Name,
+		Category:  test.Category,
+	}
+
+	// Per-test options override the run-wide ones
+	options = mergeOptions(options, test.Options)
+	if len(test.Stop) > 0 {
+		options = mergeOptions(options, map[string]interface{}{"stop": test.Stop})
+	}
+	result.Options = options
+	result.SystemPrompt = test.System
+
+	req := ollama.GenerateRequest{
+		Model:   model,
+		Prompt:  test.Prompt,
+		System:  test.System,
+		Stream:  streamResponses,
+		Options: options,
+	}
+	if warmSession {
+		req.KeepAlive = keepAliveWarm
+	}
+
+	// Snapshots describe this machine, so they are only taken for local endpoints
+	result.OllamaHost = host
+	result.Host = HostSnapshot{FreeRAMGB: -1, LoadAvg1m: -1}
+	var swapInsBefore, swapOutsBefore int64
+	swapKnown := false
+	var stopGPUSampler func() *GPUStats
+	if isLocalHost(host) {
+		result.Host = captureHostSnapshot()
+		swapInsBefore, swapOutsBefore, swapKnown = readSwapCounters()
+		if gpuSampling {
+			stopGPUSampler = startGPUSampler()
+		}
+	}
+
+	// Only streamed responses have chunks, whose arrival times give the wall-clock TTFT and token gaps
+	var firstToken, lastToken time.Time
+	var tokenTimes []time.Time
+	genResp, startTime, err := ollama.Generate(host, req, func(chunk ollama.GenerateResponse, received time.Time) {
+		if chunk.Response != "" {
+			if firstToken.IsZero() {
+				firstToken = received
+			}
+			lastToken = received
+			if traceTokens {
+				tokenTimes = append(tokenTimes, received)
+			}
+		}
+	})
+	if stopGPUSampler != nil {
+		result.GPU = stopGPUSampler()
+	}
+	if traceTokens {
+		result.TokenGaps = interTokenGaps(tokenTimes)
+	}
+	if err != nil {
+		// A broken stream keeps whatever arrived; the final chunk with Ollama's timings never came
+		if genResp.EvalCount == 0 {
+			result.Error = err.Error()
+			return result
+		}
+		result.Partial = true
+		result.Error = err.Error()
+		if lastToken.After(firstToken) {
+			genResp.EvalDuration = int64(lastToken.Sub(firstToken))
+		}
+	}
+	if !firstToken.IsZero() {
+		result.TimeToFirstToken = float64(firstToken.Sub(startTime).Microseconds()) / 1000
+		result.Streamed = true
+	}
+
+	totalTime := time.Since(startTime)
+
+	if swapKnown {
+		if swapIns, swapOuts, ok := readSwapCounters(); ok {
+			result.Host.SwapIns = swapIns - swapInsBefore
+			result.Host.SwapOuts = swapOuts - swapOutsBefore
+		}
+	}
+
+	// Calculate metrics
+	result.Success = true
+	result.Response = genResp.Response
+	result.TotalTokens = genResp.EvalCount
+	result.PromptTokens = genResp.PromptEvalCount
+	result.TotalTimeMs = float64(totalTime.Milliseconds())
+	result.RAMUsedGB = float64(estimateModelRAM(model))
+
+	if genResp.EvalDuration > 0 {
+		result.TokensPerSecond = float64(genResp.EvalCount) / float64(genResp.EvalDuration) * 1e9
+	}
+
+	if genResp.PromptEvalDuration > 0 {
+		result.PromptTokensPerSecond = float64(genResp.PromptEvalCount) / float64(genResp.PromptEvalDuration) * 1e9
+	}
+
+	result.LoadTimeMs = float64(genResp.LoadDuration) / 1e6
+	result.PromptEvalTimeMs = float64(genResp.PromptEvalDuration) / 1e6
+
+	// Without streaming, approximate time to first token from load + prompt eval time
+	if !result.Streamed && genResp.LoadDuration > 0 && genResp.PromptEvalDuration > 0 {
+		result.TimeToFirstToken = float64(genResp.LoadDuration+genResp.PromptEvalDuration) / 1e6
+	}
+
+	return result
+}
+
+// mergeOptions returns base with overrides applied on top, without modifying either map
+func mergeOptions(base, overrides map[string]interface{}) map[string]interface{} {
+	if len(base) == 0 && len(overrides) == 0 {
+		return nil
+	}
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	return merged
+}
+
+// truncateResponses returns a copy of summaries whose stored responses are cut to max characters (negative
+// keeps them whole), so long generations don't bloat exports and history; the in-memory results used for
+// the report are left untouched
+func truncateResponses(summaries []ModelSummary, max int) []ModelSummary {
+	truncated := make([]ModelSummary, len(summaries))
+	for i, s := range summaries {
+		results := make([]BenchmarkResult, len(s.TestResults))
+		for j, r := range s.TestResults {
+			r.ResponseChars = len([]rune(r.Response))
+			if max >= 0 {
+				r.Response = truncateText(r.Response, max)
+			}
+			results[j] = r
+		}
+		s.TestResults = results
+		truncated[i] = s
+	}
+	return truncated
+}
+
+// truncateText shortens s to at most max runes, marking the cut with "..."
+func truncateText(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
+
+func formatOptions(options map[string]interface{}) string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, options[key]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Repeated to build the prefill prompt; roughly 60 tokens per copy
+const prefillParagraph = "The committee reviewed the quarterly logistics report, noting that shipping delays " +
+	"at the northern warehouse had decreased after the new scheduling system was introduced, while costs in the " +
+	"southern region rose due to fuel prices and a shortage of qualified drivers during the holiday period. "
+
+// runPrefillCheck sends a prompt of about promptTokens tokens and generates a single token, so the timing is all prefill
+func runPrefillCheck(host string, model string, promptTokens int) *PrefillCheck {
+	copies := promptTokens/60 + 1
+	// A unique prefix keeps Ollama from reusing a cached prompt from an earlier run
+	prompt := fmt.Sprintf("[run %d] ", time.Now().UnixNano()) + strings.Repeat(prefillParagraph, copies) +
+		"\nSummarize the above in one word."
+
+	test := TestCase{
+		Name:   "Prefill",
+		Prompt: prompt,
+		// The default context window would silently truncate the prompt
+		Options: map[string]interface{}{"num_predict": 1, "num_ctx": promptTokens + 512},
+	}
+	result := runBenchmark(host, model, test, nil)
+	if !result.Success {
+		return &PrefillCheck{Error: result.Error}
+	}
+	return &PrefillCheck{PromptTokens: result.PromptTokens, PromptTokensPerSec: result.PromptTokensPerSecond}
+}
+
+const (
+	// Room left in the context window for the question and the answer
+	contextReserveTokens = 512
+	// Tokens generated at each size; enough for a stable decode rate
+	contextAnswerTokens = 64
+	// A drop of at least this much from the previous size is flagged as a cliff
+	contextCliffPercent = 25.0
+)
+
+// getModelContextLength returns the trained context window Ollama reports for model, 0 if unknown
+func getModelContextLength(host string, model string) int {
+	modelInfo, err := getModelInfo(host, model)
+	if err != nil {
+		return 0
+	}
+	for key, value := range modelInfo {
+		if n, ok := value.(float64); ok && strings.HasSuffix(key, ".context_length") {
+			return int(n)
+		}
+	}
+	return 0
+}
+
+// runContextScaling sends the prefill filler at each prompt size followed by a question and records speed and TTFT.
+// Every size shares one num_ctx, loaded by an untimed request first, so no size pays a reload. Sizes that
+// wouldn't fit the model's context window are reported as failed instead of being silently truncated.
+func runContextScaling(host string, model string, sizes []int) []BenchmarkResult {
+	maxContext := getModelContextLength(host, model)
+	numCtx := 0
+	var runnable []int
+	var results []BenchmarkResult
+	for _, size := range sizes {
+		if maxContext > 0 && size+contextReserveTokens > maxContext {
+			results = append(results, BenchmarkResult{
+				ModelName:   model,
+				ModelSize:   extractModelSize(model),
+				TestName:    fmt.Sprintf("Context ~%d", size),
+				Category:    "context",
+				ContextSize: size,
+				Error:       fmt.Sprintf("exceeds the model's %d-token context window", maxContext),
+			})
+			continue
+		}
+		runnable = append(runnable, size)
+		numCtx = size + contextReserveTokens
+	}
+	if len(runnable) == 0 {
+		return results
+	}
+
+	options := map[string]interface{}{"num_ctx": numCtx}
+	warmup := TestCase{Name: "Context warmup", Prompt: warmupPrompt, Options: map[string]interface{}{"num_predict": 1}}
+	if r := runBenchmark(host, model, warmup, options); !r.Success {
+		progressf("    ⚠️  Warmup at num_ctx %d failed: %s\n", numCtx, r.Error)
+	}
+
+	var measured []BenchmarkResult
+	for _, size := range runnable {
+		test := TestCase{
+			Name:     fmt.Sprintf("Context ~%d", size),
+			Category: "context",
+			// A unique prefix keeps Ollama from reusing the cached prompt of the previous size
+			Prompt: fmt.Sprintf("[run %d] ", time.Now().UnixNano()) + strings.Repeat(prefillParagraph, size/60+1) +
+				"\nIn two sentences, what happened to shipping delays and costs?",
+			Options: map[string]interface{}{"num_predict": contextAnswerTokens},
+		}
+		result := runBenchmark(host, model, test, options)
+		result.ContextSize = size
+		measured = append(measured, result)
+	}
+	return append(measured, results...)
+}
+
+// joinInts renders [256 1024] as "256, 1024"
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// runConcurrencyCheck sends the same test as k simultaneous requests and measures total throughput
+func runConcurrencyCheck(host string, model string, test TestCase, k int) *ConcurrencyCheck {
+	check := &ConcurrencyCheck{Requests: k}
+	results := make([]BenchmarkResult, k)
+
+	var wg sync.WaitGroup
+	startTime := time.Now()
+	for i := 0; i < k; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = runBenchmark(host, model, test, nil)
+		}(i)
+	}
+	wg.Wait()
+	wallTime := time.Since(startTime)
+
+	totalTokens := 0
+	totalTPS := 0.0
+	for _, r := range results {
+		if r.Success {
+			check.Succeeded++
+			totalTokens += r.TotalTokens
+			totalTPS += r.TokensPerSecond
+		}
+	}
+	check.WallTimeMs = float64(wallTime.Milliseconds())
+	if wallTime > 0 {
+		check.AggregateTokensPerSec = float64(totalTokens) / wallTime.Seconds()
+	}
+	if check.Succeeded > 0 {
+		check.PerRequestTokensPerSec = totalTPS / float64(check.Succeeded)
+	}
+	return check
+}
+
+func runThrottleCheck(host string, model string, test TestCase, settings TestSettings) *ThrottleCheck {
+	check := &ThrottleCheck{CPUSpeedLimit: -1}
+
+	for i := 0; i < settings.ThrottleCheckIterations; i++ {
+		result := runBenchmark(host, model, test, nil)
+		if !result.Success {
+			progressf("    ✗ Iteration %d failed: %s\n", i+1, result.Error)
+			continue
+		}
+		check.TokensPerSecond = append(check.TokensPerSecond, result.TokensPerSecond)
+		progressf("    Iteration %d: %s t/s\n", i+1, formatMetric(result.TokensPerSecond))
+	}
+
+	samples := check.TokensPerSecond
+	if len(samples) < 2 || samples[0] <= 0 {
+		return check
+	}
+
+	// Throughput must never recover between iterations to count as throttling
+	check.Monotonic = true
+	for i := 1; i < len(samples); i++ {
+		if samples[i] > samples[i-1] {
+			check.Monotonic = false
+			break
+		}
+	}
+
+	check.DeclinePercent = (samples[0] - samples[len(samples)-1]) / samples[0] * 100
+	check.Likely = check.Monotonic && check.DeclinePercent >= settings.ThrottleThresholdPercent
+
+	// Read the CPU speed limit right after the sustained load
+	if limit, ok := readCPUSpeedLimit(); ok {
+		check.CPUSpeedLimit = limit
+	}
+
+	return check
+}
+
+// Cores busy machine-wide over one sampling interval
+type cpuSample struct {
+	At        time.Time
+	BusyCores float64
+}
+
+// CPU inference using less than this share of the cores suggests too few threads
+const lowCoreUtilization = 0.6
+
+// startCPUSampling records busy cores every interval (from /proc/stat on Linux, summed ps %cpu elsewhere)
+// until the returned function is called, which stops sampling and returns the samples
+func startCPUSampling(interval time.Duration) func() []cpuSample {
+	stop := make(chan struct{})
+	done := make(chan []cpuSample)
+	go func() {
+		var samples []cpuSample
+		prevTotal, prevIdle, cpus, procStat := readProcStatCPU()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				done <- samples
+				return
+			case now := <-ticker.C:
+				if procStat {
+					total, idle, _, ok := readProcStatCPU()
+					if ok && total > prevTotal {
+						busy := 1 - float64(idle-prevIdle)/float64(total-prevTotal)
+						samples = append(samples, cpuSample{At: now, BusyCores: busy * float64(cpus)})
+					}
+					prevTotal, prevIdle = total, idle
+				} else if cores, ok := psBusyCores(); ok {
+					samples = append(samples, cpuSample{At: now, BusyCores: cores})
+				}
+			}
+		}
+	}()
+	return func() []cpuSample {
+		close(stop)
+		return <-done
+	}
+}
+
+// readProcStatCPU returns the aggregate jiffies, idle jiffies (including iowait) and the CPU count from /proc/stat
+func readProcStatCPU() (uint64, uint64, int, bool) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	var total, idle uint64
+	cpus := 0
+	found := false
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		if fields[0] != "cpu" {
+			cpus++
+			continue
+		}
+		found = true
+		for i, field := range fields[1:] {
+			value, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				continue
+			}
+			total += value
+			if i == 3 || i == 4 { // idle, iowait
+				idle += value
+			}
+		}
+	}
+	return total, idle, cpus, found && cpus > 0
+}
+
+// psBusyCores sums the recent %cpu of all processes; macOS reports it per core, so 100% is one busy core
+func psBusyCores() (float64, bool) {
+	output, err := exec.Command("ps", "-A", "-o", "%cpu=").Output()
+	if err != nil {
+		return 0, false
+	}
+	var sum float64
+	for _, field := range strings.Fields(string(output)) {
+		if value, err := strconv.ParseFloat(field, 64); err == nil {
+			sum += value
+		}
+	}
+	return sum / 100, true
+}
+
+// averageBusyCores averages the samples taken at or after since; -1 if there are none
+func averageBusyCores(samples []cpuSample, since time.Time) float64 {
+	var sum float64
+	count := 0
+	for _, s := range samples {
+		if !s.At.Before(since) {
+			sum += s.BusyCores
+			count++
+		}
+	}
+	if count == 0 {
+		return -1
+	}
+	return sum / float64(count)
+}
+
+func runGPUComparison(host string, model string, test TestCase) *GPUComparison {
+	comparison := &GPUComparison{}
+
+	gpuResult := runBenchmark(host, model, test, nil)
+	if !gpuResult.Success {
+		comparison.Error = "GPU run failed: " + gpuResult.Error
+		return comparison
+	}
+
+	// num_gpu is the number of layers offloaded; 0 keeps the whole model on the CPU.
+	// Changing it forces a reload, but eval timing excludes load time.
+	comparison.CPUBusyCores = -1
+	comparison.CPUCores = runtime.NumCPU()
+	var stopSampling func() []cpuSample
+	if isLocalHost(host) {
+		stopSampling = startCPUSampling(250 * time.Millisecond)
+	}
+	cpuResult := runBenchmark(host, model, test, map[string]interface{}{"num_gpu": 0})
+	finished := time.Now()
+	if stopSampling != nil {
+		samples := stopSampling()
+		// Only the generation phase counts; the reload before it is mostly I/O and would understate usage
+		if cpuResult.Success && cpuResult.TokensPerSecond > 0 {
+			evalTime := time.Duration(float64(cpuResult.TotalTokens) / cpuResult.TokensPerSecond * float64(time.Second))
+			comparison.CPUBusyCores = averageBusyCores(samples, finished.Add(-evalTime))
+		}
+	}
+	if !cpuResult.Success {
+		comparison.Error = "CPU run failed: " + cpuResult.Error
+		return comparison
+	}
+
+	comparison.GPUTokensPerSec = gpuResult.TokensPerSecond
+	comparison.CPUTokensPerSec = cpuResult.TokensPerSecond
+	if cpuResult.TokensPerSecond > 0 {
+		comparison.Speedup = gpuResult.TokensPerSecond / cpuResult.TokensPerSecond
+	}
+	return comparison
+}
+
+func readCPUSpeedLimit() (int, bool) {
+	if runtime.GOOS != "darwin" {
+		return 0, false
+	}
+
+	// pmset reports e.g. "CPU_Speed_Limit 	= 100" once a thermal event is recorded
+	output, err := exec.Command("pmset", "-g", "therm").Output()
+	if err != nil {
+		return 0, false
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.Contains(line, "CPU_Speed_Limit") {
+			parts := strings.Split(line, "=")
+			if len(parts) > 1 {
+				if limit, err := strconv.Atoi(strings.TrimSpace(parts[1])); err == nil {
+					return limit, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// Long-form prompt that keeps the model generating until num_predict is reached
+const decodeCurvePrompt = "Write a long, detailed story about a lighthouse keeper on a remote island, " +
+	"told in many chapters with rich descriptions and dialogue. Do not stop early or summarize."
+
+func runDecodeCurve(host string, model string, windowBounds []int) ([]DecodeWindow, error) {
+	req := ollama.GenerateRequest{
+		Model:   model,
+		Prompt:  decodeCurvePrompt,
+		Stream:  true,
+		Options: map[string]interface{}{"num_predict": windowBounds[len(windowBounds)-1]},
+	}
+	if warmSession {
+		req.KeepAlive = keepAliveWarm
+	}
+
+	// Ollama streams one token per chunk, so chunk arrival times are token timestamps
+	var tokenTimes []time.Time
+	_, _, err := ollama.Generate(host, req, func(chunk ollama.GenerateResponse, received time.Time) {
+		if chunk.Response != "" {
+			tokenTimes = append(tokenTimes, received)
+		}
+	})
+
+	return decodeWindows(tokenTimes, windowBounds), err
+}
+
+// decodeWindows splits token timestamps into [previous bound, bound) windows and computes
+// tokens/sec from inter-token time, so the first window excludes prompt processing
+func decodeWindows(tokenTimes []time.Time, windowBounds []int) []DecodeWindow {
+	var windows []DecodeWindow
+	start := 0
+	for _, end := range windowBounds {
+		if start >= len(tokenTimes) {
+			break
+		}
+		if end > len(tokenTimes) {
+			end = len(tokenTimes)
+		}
+
+		window := DecodeWindow{StartToken: start, EndToken: end, Tokens: end - start}
+		from := start - 1
+		if from < 0 {
+			from = 0
+		}
+		elapsed := tokenTimes[end-1].Sub(tokenTimes[from]).Seconds()
+		if elapsed > 0 {
+			window.TokensPerSecond = float64(end-1-from) / elapsed
+		}
+		windows = append(windows, window)
+		start = end
+	}
+	return windows
+}
+
+// interTokenGaps summarizes the gaps between consecutive token timestamps; nil with fewer than two tokens.
+// A steady slow model has max close to median, while stalls (GC pauses, memory pressure) show up as a max far above it.
+func interTokenGaps(tokenTimes []time.Time) *TokenGapStats {
+	if len(tokenTimes) < 2 {
+		return nil
+	}
+	gaps := make([]float64, 0, len(tokenTimes)-1)
+	for i := 1; i < len(tokenTimes); i++ {
+		gaps = append(gaps, float64(tokenTimes[i].Sub(tokenTimes[i-1]).Microseconds())/1000)
+	}
+	sort.Float64s(gaps)
+
+	median := gaps[len(gaps)/2]
+	if len(gaps)%2 == 0 {
+		median = (gaps[len(gaps)/2-1] + gaps[len(gaps)/2]) / 2
+	}
+	return &TokenGapStats{Gaps: len(gaps), MinMs: gaps[0], MedianMs: median, MaxMs: gaps[len(gaps)-1]}
+}
+
+// selectTests keeps the tests named in names (case-insensitive), in their original order
+func selectTests(tests []TestCase, names []string) ([]TestCase, error) {
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	var selected []TestCase
+	var available []string
+	for _, test := range tests {
+		key := strings.ToLower(test.Name)
+		available = append(available, fmt.Sprintf("%q", test.Name))
+		if wanted[key] {
+			selected = append(selected, test)
+			delete(wanted, key)
+		}
+	}
+	if len(wanted) > 0 {
+		var unknown []string
+		for _, name := range names {
+			if wanted[strings.ToLower(strings.TrimSpace(name))] {
+				unknown = append(unknown, fmt.Sprintf("%q", name))
+			}
+		}
+		return nil, fmt.Errorf("unknown test %s (available: %s)", strings.Join(unknown, ", "), strings.Join(available, ", "))
+	}
+	return selected, nil
+}
+
+func parseIntList(value string) ([]int, error) {
+	var sizes []int
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		size, err := strconv.Atoi(part)
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("invalid value %q (expected a positive integer)", part)
+		}
+		sizes = append(sizes, size)
+	}
+	if len(sizes) == 0 {
+		return nil, fmt.Errorf("no values given")
+	}
+	return sizes, nil
+}
+
+// Sample documents for the embedding benchmark, cycled to fill larger batches
+var embeddingSampleTexts = []string{
+	"Recursion is a technique where a function calls itself to solve smaller instances of a problem.",
+	"Paris is the capital of France and is famous for the Eiffel Tower and the Louvre.",
+	"Apple Silicon Macs share unified memory between the CPU and the GPU.",
+	"Quantization reduces model size by storing weights with fewer bits.",
+	"A train travelling at 60 mph for 2.5 hours covers 150 miles.",
+	"Vector databases index embeddings for fast similarity search.",
+	"The factorial of a number is the product of all positive integers up to it.",
+	"Retrieval-augmented generation grounds model answers in indexed documents.",
+}
+
+func runEmbeddingBenchmark(host string, model string, batchSizes []int, iterations int) []EmbeddingBatchResult {
+	if iterations < 1 {
+		iterations = 1
+	}
+
+	endpoint := detectEmbedEndpoint(host, model)
+	if endpoint == legacyEmbedEndpoint {
+		progressf("  %s is not available; using %s with one request per input\n", embedEndpoint, legacyEmbedEndpoint)
+	}
+
+	// Untimed request so the first batch size doesn't pay the model load
+	if _, _, err := runEmbeddingBatch(host, endpoint, model, embeddingSampleTexts[:1]); err != nil {
+		progressf("  ✗ Warmup failed: %v\n", err)
+	}
+
+	var results []EmbeddingBatchResult
+	for _, batchSize := range batchSizes {
+		inputs := make([]string, batchSize)
+		for i := range inputs {
+			inputs[i] = embeddingSampleTexts[i%len(embeddingSampleTexts)]
+		}
+
+		result := EmbeddingBatchResult{BatchSize: batchSize}
+		var totalLatency time.Duration
+
+		progressf("\n  Running batch size %d (%d iterations)\n", batchSize, iterations)
+		for i := 0; i < iterations; i++ {
+			embedResp, latency, err := runEmbeddingBatch(host, endpoint, model, inputs)
+			if err != nil {
+				result.Error = err.Error()
+				break
+			}
+			if len(embedResp.Embeddings) != batchSize {
+				result.Error = fmt.Sprintf("expected %d embeddings, got %d", batchSize, len(embedResp.Embeddings))
+				break
+			}
+			result.Dimensions = len(embedResp.Embeddings[0])
+			result.Iterations++
+			totalLatency += latency
+		}
+
+		if result.Iterations > 0 && result.Error == "" {
+			result.Success = true
+			result.AvgLatencyMs = float64(totalLatency.Milliseconds()) / float64(result.Iterations)
+			if result.AvgLatencyMs > 0 {
+				result.EmbeddingsPerSec = float64(batchSize) / (result.AvgLatencyMs / 1000)
+			}
+			progressf("    ✓ Latency: %sms | Embeddings/sec: %s | Dimensions: %d\n",
+				formatMetric(result.AvgLatencyMs), formatMetric(result.EmbeddingsPerSec), result.Dimensions)
+		} else {
+			progressf("    ✗ Error: %s\n", result.Error)
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// detectEmbedEndpoint probes /api/embed and falls back to /api/embeddings when the server doesn't route it.
+// Ollama answers an unknown model with a JSON error, but an unknown path with a plain-text 404.
+func detectEmbedEndpoint(host string, model string) string {
+	jsonData, err := json.Marshal(EmbedRequest{Model: model, Input: []string{"probe"}})
+	if err != nil {
+		return embedEndpoint
+	}
+	resp, err := ollama.RequestClient.Post(host+embedEndpoint, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return embedEndpoint
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Error == "" {
+			return legacyEmbedEndpoint
+		}
+	}
+	return embedEndpoint
+}
+
+// runEmbeddingBatch embeds inputs in one /api/embed request, or one /api/embeddings request per input on
+// older servers; the latency covers the whole batch either way
+func runEmbeddingBatch(host string, endpoint string, model string, inputs []string) (*EmbedResponse, time.Duration, error) {
+	if endpoint == legacyEmbedEndpoint {
+		return runLegacyEmbeddingBatch(host, model, inputs)
+	}
+	jsonData, err := json.Marshal(EmbedRequest{Model: model, Input: inputs})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	startTime := time.Now()
+	resp, err := ollama.RequestClient.Post(host+embedEndpoint,
+		"application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response: %v", err)
+	}
+	latency := time.Since(startTime)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("server returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var embedResp EmbedResponse
+	if err := json.Unmarshal(body, &embedResp); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	return &embedResp, latency, nil
+}
+
+// runLegacyEmbeddingBatch sends each input to /api/embeddings in turn and collects the vectors
+func runLegacyEmbeddingBatch(host string, model string, inputs []string) (*EmbedResponse, time.Duration, error) {
+	embedResp := &EmbedResponse{Model: model}
+	startTime := time.Now()
+	for _, input := range inputs {
+		jsonData, err := json.Marshal(LegacyEmbedRequest{Model: model, Prompt: input})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to marshal request: %v", err)
+		}
+		resp, err := ollama.RequestClient.Post(host+legacyEmbedEndpoint, "application/json", bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to send request: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, 0, fmt.Errorf("server returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+
+		var legacyResp LegacyEmbedResponse
+		if err := json.Unmarshal(body, &legacyResp); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse response: %v", err)
+		}
+		embedResp.Embeddings = append(embedResp.Embeddings, legacyResp.Embedding)
+	}
+	return embedResp, time.Since(startTime), nil
+}
+
+// Share of the responsiveness score that comes from TTFT; the rest comes from tokens/sec
+const ttftScoreWeight = 0.6
+
+// responsivenessScores blends TTFT and throughput into 0-100 per model (keyed by host and name), each relative to
+// the best of summaries: the lowest TTFT and the highest tokens/sec earn their full share
+func responsivenessScores(summaries []ModelSummary) map[string]float64 {
+	bestTTFT, bestTPS := 0.0, 0.0
+	for _, s := range summaries {
+		if s.AvgTTFTMs > 0 && (bestTTFT == 0 || s.AvgTTFTMs < bestTTFT) {
+			bestTTFT = s.AvgTTFTMs
+		}
+		bestTPS = math.Max(bestTPS, s.AvgTokensPerSec)
+	}
+	scores := map[string]float64{}
+	for _, s := range summaries {
+		score := 0.0
+		if s.AvgTTFTMs > 0 {
+			score += ttftScoreWeight * bestTTFT / s.AvgTTFTMs
+		}
+		if bestTPS > 0 {
+			score += (1 - ttftScoreWeight) * s.AvgTokensPerSec / bestTPS
+		}
+		scores[s.OllamaHost+" "+s.ModelName] = score * 100
+	}
+	return scores
+}
+
+func displayResults(summaries []ModelSummary, sysInfo *SystemInfo, tokenWeighted bool, categoryWeights map[string]float64, references []ReferenceSystem) {
+	if len(summaries) == 0 {
+		fmt.Println("No results to display.")
+		return
+	}
+
+	// Filter only successful models
+	var successful []ModelSummary
+	for _, s := range summaries {
+		if s.CanRun {
+			successful = append(successful, s)
+		}
+	}
+
+	// With several endpoints, every row names the host it ran on
+	var hosts []string
+	seenHosts := map[string]bool{}
+	for _, s := range summaries {
+		if !seenHosts[s.OllamaHost] {
+			seenHosts[s.OllamaHost] = true
+			hosts = append(hosts, s.OllamaHost)
+		}
+	}
+	label := func(s ModelSummary) string {
+		if len(hosts) > 1 {
+			return s.ModelName + " @ " + hostLabel(s.OllamaHost)
+		}
+		return s.ModelName
+	}
+
+	// Sort by average tokens per second (descending)
+	sort.Slice(successful, func(i, j int) bool {
+		return successful[i].AvgTokensPerSec > successful[j].AvgTokensPerSec
+	})
+
+	// Overall ranking
+	rankedBy := "mean"
+	if tokenWeighted {
+		rankedBy = "token-weighted mean"
+	}
+	if len(categoryWeights) > 0 {
+		rankedBy = "category-weighted " + rankedBy
+	}
+	fmt.Printf("Overall Performance Ranking (by %s tokens/sec):\n", rankedBy)
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	if len(categoryWeights) > 0 {
+		fmt.Printf("Category weights: %s (others ×1)\n", formatCategoryWeights(categoryWeights))
+	}
+	for _, s := range successful {
+		if s.Parallel {
+			fmt.Println("⚠️  parallel_testing: models ran at the same time on the same server and GPU, so each speed was measured")
+			fmt.Println("   under contention; compare these numbers with each other, not with a sequential run")
+			break
+		}
+	}
+	if fixedTokens > 0 {
+		short := 0
+		for _, s := range successful {
+			for _, r := range s.TestResults {
+				if r.Success && r.TotalTokens < fixedTokens {
+					short++
+				}
+			}
+		}
+		fmt.Printf("Fixed budget: every test was run with num_predict %d so output length is the same across models", fixedTokens)
+		if short > 0 {
+			fmt.Printf(" (%d tests ended early at end-of-sequence or a stop sequence)", short)
+		}
+		fmt.Println()
+	}
+	for i, s := range successful {
+		fmt.Printf("%d. %-25s | Size: %-8s | Passed: %d/%d | Mean: %6s t/s | Weighted: %6s t/s | Avg Time: %7s ms",
+			i+1, label(s), s.ModelSize, s.PassedTests, len(s.TestResults), formatMetric(s.MeanTokensPerSec),
+			formatMetric(s.WeightedTokensPerSec), formatMetric(s.AvgTotalTimeMs))
+		if len(categoryWeights) > 0 {
+			fmt.Printf(" | By category: %6s t/s", formatMetric(s.AvgTokensPerSec))
+		}
+		fmt.Println()
+		if len(s.Aliases) > 0 {
+			fmt.Printf("   (also tagged as: %s)\n", strings.Join(s.Aliases, ", "))
+		}
+		if s.SystemPrompt != "" {
+			fmt.Printf("   system prompt: %q\n", truncateText(s.SystemPrompt, 60))
+		}
+		if s.TestsRetried > 0 {
+			fmt.Printf("   ⚠️  flaky: %d of %d tests passed only after retrying (%d retries total)\n",
+				s.TestsRetried, len(s.TestResults), s.TotalRetries)
+		}
+		if failed := len(s.TestResults) - s.PassedTests; failed > 0 {
+			fmt.Printf("   ✗ failed: %d of %d tests never passed\n", failed, len(s.TestResults))
+		}
+		if s.ServerStopped {
+			fmt.Printf("   ⚠️  interrupted: Ollama stopped after %d tests; the rest were not run\n", len(s.TestResults))
+		}
+		if s.Preloaded {
+			fmt.Printf("   preloaded: already in memory when testing started, so no test paid the cold load\n")
+		}
+		if gpu := peakGPUStats(s.TestResults); gpu != nil {
+			fmt.Printf("   GPU peak: %s (%s)\n", formatGPUPeaks(gpu), gpu.Source)
+		}
+	}
+
+	if len(successful) > 0 && !quiet {
+		fmt.Println("\nMean: every test counts equally (how fast a typical prompt runs).")
+		fmt.Println("Weighted: tests count by tokens generated (throughput on long outputs).")
+	}
+
+	// For chat, the wait before the first token is felt more than the speed after it
+	var responsive []ModelSummary
+	for _, s := range successful {
+		if s.AvgTTFTMs > 0 {
+			responsive = append(responsive, s)
+		}
+	}
+	if len(responsive) > 0 {
+		scores := responsivenessScores(responsive)
+		sort.SliceStable(responsive, func(i, j int) bool {
+			return responsive[i].AvgTTFTMs < responsive[j].AvgTTFTMs
+		})
+		fmt.Println("\n\nResponsiveness Ranking (by mean time to first token, lowest first):")
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		for i, s := range responsive {
+			fmt.Printf("%d. %-25s | TTFT: %7s ms | %6s t/s | Score: %5.1f\n", i+1, label(s),
+				formatMetric(s.AvgTTFTMs), formatMetric(s.AvgTokensPerSec), scores[s.OllamaHost+" "+s.ModelName])
+		}
+		if !quiet {
+			fmt.Printf("\nScore: 0-100 relative to this run, %.0f%% for the lowest TTFT and %.0f%% for the highest tokens/sec.\n",
+				ttftScoreWeight*100, (1-ttftScoreWeight)*100)
+		}
+	}
+
+	// Category breakdown (omitted in quiet mode)
+	categories := map[string]bool{}
+	if !quiet {
+		for _, s := range successful {
+			for _, r := range s.TestResults {
+				if r.Success {
+					categories[r.Category] = true
+				}
+			}
+		}
+	}
+
+	for category := range categories {
+		fmt.Printf("\n\nCategory: %s\n", category)
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+		for _, s := range successful {
+			for _, r := range s.TestResults {
+				if r.Category == category && r.Success {
+					speed := formatMetric(r.TokensPerSecond)
+					if r.Repetitions > 1 {
+						speed += " ± " + formatMetric(r.TokensPerSecStdDev)
+					}
+					fmt.Printf("%-25s | %6s t/s | %7s ms | %d tokens",
+						label(s), speed, formatMetric(r.TotalTimeMs), r.TotalTokens)
+					if len(r.Options) > 0 {
+						fmt.Printf(" | %s", formatOptions(r.Options))
+					}
+					if r.Partial {
+						fmt.Print(" | partial stream")
+					}
+					fmt.Println()
+				}
+			}
+		}
+	}
+
+	// Best model for each category
+	if !quiet {
+		fmt.Println("\n\nBest Model for Each Category:")
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	}
+	for _, best := range bestPerCategory(successful) {
+		if categories[best.Category] {
+			fmt.Printf("%-15s: %s (%s t/s)\n", best.Category, label(best.Summary), formatMetric(best.TokensPerSec))
+		}
+	}
+
+	// Thermal throttling check
+	var throttled []ModelSummary
+	for _, s := range successful {
+		if s.Throttle != nil {
+			throttled = append(throttled, s)
+		}
+	}
+	if len(throttled) > 0 {
+		fmt.Println("\n\nThermal Throttling Check:")
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		for _, s := range throttled {
+			status := "✓ stable"
+			if s.Throttle.Likely {
+				status = "⚠️  thermal throttling likely"
+			}
+			speedLimit := "n/a"
+			if s.Throttle.CPUSpeedLimit >= 0 {
+				speedLimit = fmt.Sprintf("%d%%", s.Throttle.CPUSpeedLimit)
+			}
+			fmt.Printf("%-25s | %2d runs | %5.1f%% decline | CPU speed limit: %-4s | %s\n",
+				label(s), len(s.Throttle.TokensPerSecond), s.Throttle.DeclinePercent, speedLimit, status)
+			if s.Throttle.CPUSpeedLimit >= 0 && s.Throttle.CPUSpeedLimit < 100 {
+				fmt.Printf("  pmset confirms the CPU is limited to %d%% of its maximum speed\n", s.Throttle.CPUSpeedLimit)
+			}
+		}
+	}
+
+	// Prompt ingestion speed
+	headerPrinted := false
+	for _, s := range successful {
+		if s.Prefill == nil || s.Prefill.Error != "" {
+			continue
+		}
+		if !headerPrinted {
+			fmt.Println("\n\nPrompt Ingestion (prefill, num_predict 1):")
+			fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+			headerPrinted = true
+		}
+		fmt.Printf("%-25s | %6d prompt tokens | %8s prompt t/s | decode: %6s t/s\n",
+			label(s), s.Prefill.PromptTokens, formatMetric(s.Prefill.PromptTokensPerSec), formatMetric(s.AvgTokensPerSec))
+	}
+
+	// Startup cost vs steady-state throughput
+	headerPrinted = false
+	for _, s := range successful {
+		if s.ColdWarm == nil {
+			continue
+		}
+		if !headerPrinted {
+			fmt.Println("\n\nCold vs Warm (first request after unloading vs the rest):")
+			fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+			fmt.Printf("%-25s | %-45s || %s\n", "", "Cold (first request)", "Warm (mean of later requests)")
+			fmt.Printf("%-25s | %9s | %9s | %9s | %7s || %9s | %9s | %7s\n", "Model",
+				"Load ms", "TTFT ms", "Total ms", "t/s", "TTFT ms", "Total ms", "t/s")
+			headerPrinted = true
+		}
+		c := s.ColdWarm
+		fmt.Printf("%-25s | %9s | %9s | %9s | %7s || %9s | %9s | %7s\n", label(s),
+			formatMetric(c.ColdLoadMs), formatMetric(c.ColdTTFTMs), formatMetric(c.ColdTotalMs), formatMetric(c.ColdTokensPerSec),
+			formatMetric(c.WarmTTFTMs), formatMetric(c.WarmTotalMs), formatMetric(c.WarmTokensPerSec))
+	}
+
+	// Aggregate throughput under parallel requests
+	headerPrinted = false
+	for _, s := range successful {
+		if s.Concurrency == nil {
+			continue
+		}
+		if !headerPrinted {
+			fmt.Println("\n\nConcurrent Throughput (same prompt, parallel requests):")
+			fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+			headerPrinted = true
+		}
+		fmt.Printf("%-25s | %2d requests (%d ok) | Aggregate: %6s t/s | Per request: %6s t/s | Wall: %7s ms\n",
+			label(s), s.Concurrency.Requests, s.Concurrency.Succeeded, formatMetric(s.Concurrency.AggregateTokensPerSec),
+			formatMetric(s.Concurrency.PerRequestTokensPerSec), formatMetric(s.Concurrency.WallTimeMs))
+	}
+
+	// Tests that ran while the host was swapping are likely outliers
+	for _, s := range successful {
+		for _, r := range s.TestResults {
+			if r.Success && r.Host.SwapOuts > 0 {
+				fmt.Printf("\n⚠️  %s / %s ran while the system was swapping (%s)", label(s), r.TestName, formatHostSnapshot(r.Host))
+			}
+		}
+	}
+
+	// Measured GPU speedup per model
+	headerPrinted = false
+	for _, s := range successful {
+		if s.GPUComparison == nil || s.GPUComparison.Error != "" {
+			continue
+		}
+		if !headerPrinted {
+			fmt.Println("\n\nGPU Acceleration (default offload vs num_gpu:0):")
+			fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+			headerPrinted = true
+		}
+		fmt.Printf("%-25s | GPU: %6s t/s | CPU: %6s t/s | Speedup: %5sx", label(s),
+			formatMetric(s.GPUComparison.GPUTokensPerSec), formatMetric(s.GPUComparison.CPUTokensPerSec), formatMetric(s.GPUComparison.Speedup))
+		if s.GPUComparison.CPUBusyCores >= 0 {
+			fmt.Printf(" | CPU run: %.1f/%d cores busy", s.GPUComparison.CPUBusyCores, s.GPUComparison.CPUCores)
+		}
+		fmt.Println()
+		if s.GPUComparison.CPUBusyCores >= 0 && s.GPUComparison.CPUBusyCores < lowCoreUtilization*float64(s.GPUComparison.CPUCores) {
+			fmt.Printf("  ⚠️  CPU inference kept only %.0f%% of the cores busy; raise the num_thread option (OLLAMA_NUM_THREADS) if Ollama is limited to fewer threads\n",
+				s.GPUComparison.CPUBusyCores/float64(s.GPUComparison.CPUCores)*100)
+		}
+	}
+
+	// Speed scaled by model size, so the sweet spot of the hardware stands out instead of the smallest model
+	var byParams []ModelSummary
+	for _, s := range successful {
+		if s.ParamTokensPerSec > 0 {
+			byParams = append(byParams, s)
+		}
+	}
+	sort.SliceStable(byParams, func(i, j int) bool {
+		return byParams[i].ParamTokensPerSec > byParams[j].ParamTokensPerSec
+	})
+	if len(byParams) > 0 {
+		fmt.Println("\n\nHardware Utilization Ranking (tokens/sec × billions of parameters):")
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		for i, s := range byParams {
+			fmt.Printf("%d. %-25s | Params: %6.2fB | %6s t/s | %8s B·t/s\n", i+1, label(s), s.ParamsBillions,
+				formatMetric(s.AvgTokensPerSec), formatMetric(s.ParamTokensPerSec))
+		}
+	}
+
+	// How far estimateModelRAM is from what Ollama actually allocated
+	headerPrinted = false
+	var totalAbsError, totalError float64
+	measuredCount := 0
+	for _, s := range successful {
+		if s.MeasuredRAMGB <= 0 {
+			continue
+		}
+		if !headerPrinted {
+			fmt.Println("\n\nRAM Estimate Accuracy (estimated vs loaded size from /api/ps):")
+			fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+			headerPrinted = true
+		}
+		errorPct := (float64(s.EstimatedRAMGB) - s.MeasuredRAMGB) / s.MeasuredRAMGB * 100
+		totalError += errorPct
+		totalAbsError += math.Abs(errorPct)
+		measuredCount++
+		fmt.Printf("%-25s | Estimated: %3d GB | Measured: %6.2f GB | Error: %+6.1f%%\n",
+			label(s), s.EstimatedRAMGB, s.MeasuredRAMGB, errorPct)
+	}
+	if measuredCount > 0 {
+		bias := totalError / float64(measuredCount)
+		direction := "high"
+		if bias < 0 {
+			direction = "low"
+		}
+		fmt.Printf("Estimation accuracy: %.1f%% mean absolute error over %d models; estimates run %.1f%% %s on average\n",
+			totalAbsError/float64(measuredCount), measuredCount, math.Abs(bias), direction)
+	}
+
+	// Decode speed as the context fills
+	headerPrinted = false
+	for _, s := range successful {
+		if len(s.DecodeCurve) == 0 {
+			continue
+		}
+		if !headerPrinted {
+			fmt.Println("\n\nDecode Speed by Context Fill:")
+			fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+			headerPrinted = true
+		}
+		fmt.Printf("%-25s", label(s))
+		for _, w := range s.DecodeCurve {
+			fmt.Printf(" | %d-%d: %6s t/s", w.StartToken, w.EndToken, formatMetric(w.TokensPerSecond))
+		}
+		fmt.Println()
+	}
+
+	// Prompt size vs speed, with the first big drop marked
+	headerPrinted = false
+	for _, s := range successful {
+		if len(s.ContextScaling) == 0 {
+			continue
+		}
+		if !headerPrinted {
+			fmt.Println("\n\nContext Length Scaling (prompt size vs speed):")
+			fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+			fmt.Printf("%-25s | %8s | %8s | %10s | %9s\n", "Model", "Prompt", "Measured", "TTFT ms", "t/s")
+			headerPrinted = true
+		}
+		name := label(s)
+		previous := 0.0
+		for _, r := range s.ContextScaling {
+			if !r.Success {
+				fmt.Printf("%-25s | %8d | skipped: %s\n", name, r.ContextSize, r.Error)
+				name = ""
+				continue
+			}
+			cliff := ""
+			if previous > 0 && r.TokensPerSecond < previous*(1-contextCliffPercent/100) {
+				cliff = fmt.Sprintf("  ◀ %.0f%% slower", (1-r.TokensPerSecond/previous)*100)
+			}
+			fmt.Printf("%-25s | %8d | %8d | %10s | %9s%s\n", name, r.ContextSize, r.PromptTokens,
+				formatMetric(r.TimeToFirstToken), formatMetric(r.TokensPerSecond), cliff)
+			previous = r.TokensPerSecond
+			name = ""
+		}
+	}
+
+	// This machine's speed as a share of each reference system's, for models both have numbers for
+	headerPrinted = false
+	for _, s := range successful {
+		var parts []string
+		for _, ref := range references {
+			if refTPS, ok := referenceTokensPerSec(ref, s); ok {
+				parts = append(parts, fmt.Sprintf("%s: %.0f%%", ref.Name, s.AvgTokensPerSec/refTPS*100))
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		if !headerPrinted {
+			fmt.Println("\n\nCompared to Reference Systems (your tokens/sec as % of theirs):")
+			fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+			for _, ref := range references {
+				if ref.Note != "" {
+					fmt.Printf("%s: %s\n", ref.Name, ref.Note)
+				}
+			}
+			headerPrinted = true
+		}
+		fmt.Printf("%-25s | %6s t/s | %s\n", label(s), formatMetric(s.AvgTokensPerSec), strings.Join(parts, " | "))
+	}
+
+	// Same model, tokens/sec per host
+	if len(hosts) > 1 {
+		fmt.Println("\n\nCross-Machine Comparison (avg tokens/sec):")
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		fmt.Printf("%-25s", "Model")
+		for _, h := range hosts {
+			fmt.Printf(" | %20s", hostLabel(h))
+		}
+		fmt.Println()
+
+		var models []string
+		byHost := map[string]map[string]ModelSummary{}
+		for _, s := range summaries {
+			if byHost[s.ModelName] == nil {
+				byHost[s.ModelName] = map[string]ModelSummary{}
+				models = append(models, s.ModelName)
+			}
+			byHost[s.ModelName][s.OllamaHost] = s
+		}
+		for _, model := range models {
+			fmt.Printf("%-25s", model)
+			for _, h := range hosts {
+				s, ok := byHost[model][h]
+				if !ok || !s.CanRun {
+					fmt.Printf(" | %20s", "n/a")
+					continue
+				}
+				fmt.Printf(" | %16s t/s", formatMetric(s.AvgTokensPerSec))
+			}
+			fmt.Println()
+		}
+	}
+
+	// Recommendations
+	fmt.Println("\n\n=== Recommendations for Your System ===")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	if pick, ok := recommendForMachine(successful, sysInfo); ok {
+		fmt.Printf("★ Recommended for your machine: %s (%s t/s, %.1f GB RAM headroom of %d GB available)\n",
+			label(pick.Summary), formatMetric(pick.Summary.AvgTokensPerSec), pick.HeadroomGB, sysInfo.AvailableRAMGB)
+	} else if len(successful) > 0 {
+		fmt.Printf("★ No model is both usably fast (≥ %.0f t/s) and leaves %.0f%% of RAM free; see the ranking above\n",
+			usableTokensPerSec, comfortableHeadroom*100)
+	}
+	if len(successful) > 0 {
+		fmt.Printf("✓ Best overall performer: %s (%s t/s)\n",
+			label(successful[0]), formatMetric(successful[0].AvgTokensPerSec))
+
+		// Find smallest working model
+		var smallest *ModelSummary
+		for i := range successful {
+			if smallest == nil || estimateModelRAM(successful[i].ModelName) < estimateModelRAM(smallest.ModelName) {
+				smallest = &successful[i]
+			}
+		}
+		if smallest != nil {
+			fmt.Printf("✓ Most efficient (smallest): %s (~%.0f GB RAM)\n",
+				label(*smallest), float64(estimateModelRAM(smallest.ModelName)))
+		}
+	}
+
+	fmt.Printf("\nSystem capacity: %d GB RAM available for LLMs\n", sysInfo.AvailableRAMGB)
+	fmt.Printf("Architecture: %s\n", sysInfo.Arch)
+	if sysInfo.Virtualization != "" {
+		fmt.Printf("⚠️  Results reflect a virtualized environment (%s)\n", sysInfo.Virtualization)
+	}
+
+	if sysInfo.Arch == "arm64" {
+		fmt.Println("✓ Apple Silicon detected - excellent performance with Metal API")
+	}
+}
+
+func loadReferenceSystems(filename string) ([]ReferenceSystem, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var references []ReferenceSystem
+	if err := json.Unmarshal(data, &references); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return references, nil
+}
+
+// referenceTokensPerSec looks a summary up in a reference system by its tag or any alias of it
+func referenceTokensPerSec(ref ReferenceSystem, s ModelSummary) (float64, bool) {
+	for _, name := range append([]string{s.ModelName}, s.Aliases...) {
+		if tps, ok := ref.TokensPerSec[name]; ok && tps > 0 {
+			return tps, true
+		}
+	}
+	return 0, false
+}
+
+// hostLabel drops the scheme so report columns stay narrow
+func hostLabel(host string) string {
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return host
+}
+
+// Thresholds for the combined recommendation: fast enough to read along with, and small enough to leave the
+// rest of the system room to work
+const (
+	usableTokensPerSec  = 10.0
+	comfortableHeadroom = 0.25 // fraction of available RAM left free
+)
+
+// A model picked by recommendForMachine, with the RAM it leaves free
+type MachineRecommendation struct {
+	Summary    ModelSummary
+	HeadroomGB float64
+}
+
+// recommendForMachine combines the checker's question (does it fit comfortably?) with the benchmark's
+// (is it fast?): among local models that are usably fast and leave comfortable headroom, it picks the one
+// moving the most parameters per second, so a tiny model doesn't win on raw speed alone.
+func recommendForMachine(summaries []ModelSummary, sysInfo *SystemInfo) (MachineRecommendation, bool) {
+	var best MachineRecommendation
+	found := false
+	for _, s := range summaries {
+		if !isLocalHost(s.OllamaHost) || s.AvgTokensPerSec < usableTokensPerSec {
+			continue
+		}
+		ramGB := s.MeasuredRAMGB
+		if ramGB <= 0 {
+			required, _ := modelRAMRequirement(s.ModelName, installedSizes(s.OllamaHost))
+			ramGB = float64(required)
+		}
+		headroom := float64(sysInfo.AvailableRAMGB) - ramGB
+		if headroom < float64(sysInfo.AvailableRAMGB)*comfortableHeadroom {
+			continue
+		}
+		// Without a parameter count ParamTokensPerSec is 0, so raw speed breaks the tie
+		if !found || s.ParamTokensPerSec > best.Summary.ParamTokensPerSec ||
+			(s.ParamTokensPerSec == best.Summary.ParamTokensPerSec && s.AvgTokensPerSec > best.Summary.AvgTokensPerSec) {
+			best = MachineRecommendation{Summary: s, HeadroomGB: headroom}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// The first generation of a session, compared with how much later models slowed down on their own first test
+type GPUColdStart struct {
+	FirstModel   string
+	FirstRatio   float64 // first test's tokens/sec / mean of the model's other tests
+	TypicalRatio float64 // median of the same ratio over the models that followed
+	Models       int     // later models the typical ratio is based on
+	Likely       bool
+}
+
+// A session's first model counts as GPU-cold when its first-test slowdown is this much worse than usual
+const gpuColdStartThreshold = 0.8
+
+// firstTestRatio is a model's first-test tokens/sec relative to the mean of its other successful tests;
+// comparing ratios rather than raw speeds cancels out model size
+func firstTestRatio(s ModelSummary) (float64, bool) {
+	if len(s.TestResults) < 2 || !s.TestResults[0].Success || s.TestResults[0].TokensPerSecond <= 0 {
+		return 0, false
+	}
+	var rest float64
+	count := 0
+	for _, r := range s.TestResults[1:] {
+		if r.Success && r.TokensPerSecond > 0 {
+			rest += r.TokensPerSecond
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return s.TestResults[0].TokensPerSecond / (rest / float64(count)), true
+}
+
+// detectGPUColdStart compares the session's first model's first-test slowdown with the later models' on the
+// same endpoint. Each model pays its own load cost on its first test, so only an extra slowdown on the very first
+// generation points at GPU state (shader compilation, allocation) rather than the model. nil with fewer than two models.
+func detectGPUColdStart(summaries []ModelSummary) *GPUColdStart {
+	if len(summaries) < 2 {
+		return nil
+	}
+	first := summaries[0]
+	firstRatio, ok := firstTestRatio(first)
+	if !ok {
+		return nil
+	}
+
+	var ratios []float64
+	for _, s := range summaries[1:] {
+		if s.OllamaHost != first.OllamaHost {
+			continue
+		}
+		if ratio, ok := firstTestRatio(s); ok {
+			ratios = append(ratios, ratio)
+		}
+	}
+	if len(ratios) == 0 {
+		return nil
+	}
+	sort.Float64s(ratios)
+	typical := ratios[len(ratios)/2]
+	if len(ratios)%2 == 0 {
+		typical = (ratios[len(ratios)/2-1] + ratios[len(ratios)/2]) / 2
+	}
+
+	return &GPUColdStart{
+		FirstModel:   first.ModelName,
+		FirstRatio:   firstRatio,
+		TypicalRatio: typical,
+		Models:       len(ratios),
+		Likely:       firstRatio < typical*gpuColdStartThreshold,
+	}
+}
+
+func displayGPUColdStart(check *GPUColdStart) {
+	fmt.Println("\n\nGPU Cold Start (first generation of the session):")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	if check == nil {
+		fmt.Println("Not enough data: needs at least two models on the same host whose first and later tests succeeded.")
+		return
+	}
+	fmt.Printf("%s's first test ran at %.0f%% of its later tests; later models' first tests ran at %.0f%% (median of %d)\n",
+		check.FirstModel, check.FirstRatio*100, check.TypicalRatio*100, check.Models)
+	if check.Likely {
+		fmt.Printf("⚠️  The session's first generation was anomalously slow, suggesting a GPU cold start.\n")
+		fmt.Printf("   %s may rank lower than it should; re-run it or start with a small throwaway model.\n", check.FirstModel)
+	} else {
+		fmt.Println("✓ No sign of a GPU cold start")
+	}
+}
+
+// displayNewlyPulled lists the models downloaded during this run and the disk space they now take
+func displayNewlyPulled(summaries []ModelSummary) {
+	var pulled []ModelSummary
+	for _, s := range summaries {
+		if s.Pulled {
+			pulled = append(pulled, s)
+		}
+	}
+	if len(pulled) == 0 {
+		return
+	}
+
+	// Sizes come from each host's own inventory
+	sizes := make(map[string]map[string]int64)
+	for _, s := range pulled {
+		if sizes[s.OllamaHost] != nil {
+			continue
+		}
+		sizes[s.OllamaHost] = installedSizes(s.OllamaHost)
+	}
+
+	fmt.Println("\n\nNewly Pulled This Run:")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	var total int64
+	for _, s := range pulled {
+		size := sizes[s.OllamaHost][s.ModelName]
+		total += size
+		name := s.ModelName
+		if len(sizes) > 1 {
+			name += " @ " + hostLabel(s.OllamaHost)
+		}
+		fmt.Printf("%-35s | %6.1f GB\n", name, float64(size)/(1024*1024*1024))
+	}
+	fmt.Printf("%d models, %.1f GB of new disk usage. Remove any you don't want with: ollama rm <model>\n",
+		len(pulled), float64(total)/(1024*1024*1024))
+}
+
+// embeddingModelPrefixes are embedding families whose names don't contain "embed"
+var embeddingModelPrefixes = []string{"all-minilm", "bge-", "paraphrase-multilingual", "granite-embedding"}
+
+// isEmbeddingModel reports whether model only serves embeddings, judged by its name.
+// Such models reject /api/generate, so the generation tests would all fail.
+func isEmbeddingModel(model string) bool {
+	name := strings.ToLower(model)
+	if strings.Contains(name, "embed") {
+		return true
+	}
+	for _, prefix := range embeddingModelPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// benchmarkEmbeddingModel pulls model if needed and measures its embedding throughput in place of the
+// generation tests. The summary has CanRun false so rankings built on tokens/sec leave it out.
+func benchmarkEmbeddingModel(host string, model string, batchSizes []int, iterations int, config *Config) ModelSummary {
+	summary := ModelSummary{ModelName: model, OllamaHost: host, ModelSize: extractModelSize(model)}
+
+	if !checkModelInstalled(host, model) {
+		if !config.TestSettings.AutoPullModels {
+			progressf("Model %s not installed. Skipping (auto_pull disabled)...\n", model)
+			summary.SkipReason = "Model not installed and auto_pull is disabled"
+			return summary
+		}
+		progressf("Model %s not installed. Pulling model...\n", model)
+		if err := pullModel(host, model); err != nil {
+			progressf("Failed to pull %s: %v\n", model, err)
+			if hint := ollama.PullFailureHint(err); hint != "" {
+				progressf("  → %s\n", hint)
+			}
+			summary.SkipReason = fmt.Sprintf("Failed to pull model: %v", err)
+			return summary
+		}
+		summary.Pulled = true
+	}
+
+	progressf("\n=== Embedding Benchmark: %s ===\n", model)
+	summary.Embedding = runEmbeddingBenchmark(host, model, batchSizes, iterations)
+	summary.SkipReason = "Embedding model: measured embeddings/sec instead of the generation tests"
+	return summary
+}
+
+func displayEmbeddingResults(model string, results []EmbeddingBatchResult) {
+	fmt.Printf("\n\n=== Embedding Throughput: %s ===\n\n", model)
+	fmt.Println("Batch Size | Avg Latency | Embeddings/sec | Dimensions")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	var best *EmbeddingBatchResult
+	for i, r := range results {
+		if !r.Success {
+			fmt.Printf("%10d | failed: %s\n", r.BatchSize, r.Error)
+			continue
+		}
+		fmt.Printf("%10d | %8s ms | %14s | %d\n",
+			r.BatchSize, formatMetric(r.AvgLatencyMs), formatMetric(r.EmbeddingsPerSec), r.Dimensions)
+		if best == nil || r.EmbeddingsPerSec > best.EmbeddingsPerSec {
+			best = &results[i]
+		}
+	}
+
+	if best != nil {
+		fmt.Printf("\n✓ Optimal batch size: %d (%s embeddings/sec)\n", best.BatchSize, formatMetric(best.EmbeddingsPerSec))
+	}
+}
+
+// One benchmark run, as written to the history file and the JSON export
+type RunRecord struct {
+	SchemaVersion  int            `json:"schema_version"` // resultsSchemaVersion when written; 0 in files from before versioning
+	Timestamp      time.Time      `json:"timestamp"`
+	Arch           string         `json:"arch"`
+	TotalRAMGB     int64          `json:"total_ram_gb"`
+	Virtualization string         `json:"virtualization,omitempty"`
+	FixedTokens    int            `json:"fixed_tokens,omitempty"`
+	Metadata       *RunMetadata   `json:"metadata,omitempty"` // absent in runs recorded before it was added
+	Summaries      []ModelSummary `json:"summaries"`
+}
+
+// Layout version of RunRecord in -json exports and the history file. Bump it when a field is renamed, removed
+// or changes meaning, so readers can tell old files apart. Version 0 (no field) has the same layout as 1.
+const resultsSchemaVersion = 1
+
+// Provenance of a run: enough to tell later how a result file was produced
+type RunMetadata struct {
+	ToolCommit     string                       `json:"tool_commit"`     // git describe of the working directory, "unknown" outside a checkout
+	OllamaVersions map[string]string            `json:"ollama_versions"` // endpoint -> version from /api/version
+	GoVersion      string                       `json:"go_version"`
+	OS             string                       `json:"os"`
+	CPUCores       int                          `json:"cpu_cores"`
+	Args           []string                     `json:"args"`
+	ConfigFile     string                       `json:"config_file"`
+	Config         *Config                      `json:"config"`
+	System         *SystemInfo                  `json:"system"`
+	ServerSettings map[string]map[string]string `json:"server_settings,omitempty"` // endpoint -> server-global env settings, local endpoints only
+}
+
+// redactArgs copies the command line with the values of -auth-token and -header replaced, so credentials don't end
+// up in exports and the history file
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 0; i < len(redacted); i++ {
+		name := strings.TrimLeft(redacted[i], "-")
+		if value := strings.SplitN(name, "=", 2); len(value) == 2 && (value[0] == "auth-token" || value[0] == "header") {
+			redacted[i] = redacted[i][:len(redacted[i])-len(value[1])] + redactedValue(value[0], value[1])
+		} else if (name == "auth-token" || name == "header") && i+1 < len(redacted) {
+			redacted[i+1] = redactedValue(name, redacted[i+1])
+			i++
+		}
+	}
+	return redacted
+}
+
+// redactedValue hides a credential flag's value; a header keeps its name so the export still says what was sent
+func redactedValue(flagName, value string) string {
+	if flagName == "header" {
+		if sep := strings.IndexAny(value, "=:"); sep > 0 {
+			return value[:sep+1] + "REDACTED"
+		}
+	}
+	return "REDACTED"
+}
+
+// collectRunMetadata records the tool revision, Ollama versions, command line, config and system of this run
+func collectRunMetadata(hosts []string, configFile string, config *Config, sysInfo *SystemInfo) *RunMetadata {
+	meta := &RunMetadata{
+		ToolCommit:     "unknown",
+		OllamaVersions: make(map[string]string),
+		GoVersion:      runtime.Version(),
+		OS:             runtime.GOOS,
+		CPUCores:       runtime.NumCPU(),
+		Args:           redactArgs(os.Args[1:]),
+		ConfigFile:     configFile,
+		Config:         config,
+		System:         sysInfo,
+	}
+	if output, err := exec.Command("git", "describe", "--always", "--dirty").Output(); err == nil {
+		meta.ToolCommit = strings.TrimSpace(string(output))
+	}
+	versions := make([]string, len(hosts))
+	settings := make([]map[string]string, len(hosts))
+	forEachBounded(len(hosts), config.TestSettings.DiscoveryConcurrency, func(i int) {
+		versions[i] = getOllamaVersion(hosts[i])
+		settings[i], _ = readServerSettings(hosts[i])
+	})
+	for i, host := range hosts {
+		meta.OllamaVersions[host] = versions[i]
+		if settings[i] != nil {
+			if meta.ServerSettings == nil {
+				meta.ServerSettings = make(map[string]map[string]string)
+			}
+			meta.ServerSettings[host] = settings[i]
+		}
+	}
+	return meta
+}
+
+// Server-global settings that change throughput. Ollama reads them from its environment at startup and
+// offers no per-request option for them, so they can only be compared across runs (see the compare subcommand).
+var serverSettingNames = []string{"OLLAMA_FLASH_ATTENTION", "OLLAMA_KV_CACHE_TYPE"}
+
+// readServerSettings returns the performance settings of a local Ollama server and where they were read
+// from: the running server's environment (Linux /proc, macOS launchctl), else this shell's environment.
+// Remote endpoints return nil because their environment can't be inspected.
+func readServerSettings(host string) (map[string]string, string) {
+	if !isLocalHost(host) {
+		return nil, ""
+	}
+	env, source := ollamaProcessEnv()
+	settings := make(map[string]string)
+	for _, name := range serverSettingNames {
+		value := env[name]
+		if value == "" {
+			value = "unset"
+		}
+		settings[name] = value
+	}
+	return settings, source
+}
+
+// ollamaProcessEnv reads the environment of the running ollama server, falling back to this process's
+// environment (which matches when the server was started from the same shell)
+func ollamaProcessEnv() (map[string]string, string) {
+	env := make(map[string]string)
+	if runtime.GOOS == "darwin" {
+		// The menu bar app's server takes its settings from launchctl setenv
+		found := false
+		for _, name := range serverSettingNames {
+			if output, err := exec.Command("launchctl", "getenv", name).Output(); err == nil {
+				if value := strings.TrimSpace(string(output)); value != "" {
+					env[name] = value
+					found = true
+				}
+			}
+		}
+		if found {
+			return env, "launchctl"
+		}
+		return shellServerEnv(), "shell environment"
+	}
+
+	procs, _ := filepath.Glob("/proc/[0-9]*/comm")
+	for _, commFile := range procs {
+		comm, err := os.ReadFile(commFile)
+		if err != nil || strings.TrimSpace(string(comm)) != "ollama" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(commFile), "environ"))
+		if err != nil {
+			continue
+		}
+		for _, entry := range strings.Split(string(data), "\x00") {
+			if i := strings.Index(entry, "="); i > 0 {
+				env[entry[:i]] = entry[i+1:]
+			}
+		}
+		return env, "ollama process"
+	}
+	return shellServerEnv(), "shell environment"
+}
+
+// shellServerEnv picks the server settings out of this process's environment
+func shellServerEnv() map[string]string {
+	env := make(map[string]string)
+	for _, name := range serverSettingNames {
+		env[name] = os.Getenv(name)
+	}
+	return env
+}
+
+// formatServerSettings renders settings as "NAME=value" pairs in serverSettingNames order
+func formatServerSettings(settings map[string]string) string {
+	var pairs []string
+	for _, name := range serverSettingNames {
+		if value, ok := settings[name]; ok {
+			pairs = append(pairs, name+"="+value)
+		}
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// getOllamaVersion returns the server version, or "unknown" if /api/version can't be read
+func getOllamaVersion(host string) string {
+	resp, err := ollama.MetadataClient.Get(host + "/api/version")
+	if err != nil {
+		return "unknown"
+	}
+	defer resp.Body.Close()
+
+	var version struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil || version.Version == "" {
+		return "unknown"
+	}
+	return version.Version
+}
+
+// metadataLines renders the provenance as "key: value" lines for the non-JSON reports
+func metadataLines(run RunRecord) []string {
+	lines := []string{"timestamp: " + run.Timestamp.Format(time.RFC3339)}
+	meta := run.Metadata
+	if meta == nil {
+		return lines
+	}
+	var versions []string
+	for host, version := range meta.OllamaVersions {
+		versions = append(versions, hostLabel(host)+" "+version)
+	}
+	sort.Strings(versions)
+	args := make([]string, len(meta.Args))
+	for i, arg := range meta.Args {
+		args[i] = arg
+		if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+			args[i] = strconv.Quote(arg)
+		}
+	}
+	lines = append(lines,
+		"tool commit: "+meta.ToolCommit,
+		"ollama: "+strings.Join(versions, ", "),
+		fmt.Sprintf("system: %s/%s, %d cores, %d GB RAM (%s)", meta.OS, run.Arch, meta.CPUCores, run.TotalRAMGB, meta.System.TotalRAMSource),
+		"go: "+meta.GoVersion,
+		"config: "+meta.ConfigFile,
+		"args: "+strings.Join(args, " "),
+	)
+	if run.Virtualization != "" {
+		lines = append(lines, "virtualization: "+run.Virtualization)
+	}
+	var hosts []string
+	for host := range meta.ServerSettings {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		lines = append(lines, fmt.Sprintf("server settings (%s): %s", hostLabel(host), formatServerSettings(meta.ServerSettings[host])))
+	}
+	return lines
+}
+
+// appendHistory adds a run to the JSONL history file, then applies the rotation policy
+func appendHistory(settings HistorySettings, run RunRecord) error {
+	line, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(settings.File, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	// Start on a fresh line if a previous run was interrupted mid-write
+	if stat, err := f.Stat(); err == nil && stat.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, stat.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if settings.KeepRuns > 0 || settings.KeepDays > 0 {
+		return rotateHistory(settings, run.Timestamp)
+	}
+	return nil
+}
+
+// rotateHistory drops runs beyond keep_runs or older than keep_days. The kept runs are written to a
+// temp file in the same directory and renamed over the original, so a crash leaves either the old or
+// the new file intact, never a partial one.
+func rotateHistory(settings HistorySettings, now time.Time) error {
+	runs, err := loadHistory(settings.File)
+	if err != nil {
+		return err
+	}
+
+	kept := runs
+	if settings.KeepDays > 0 {
+		cutoff := now.AddDate(0, 0, -settings.KeepDays)
+		kept = nil
+		for _, run := range runs {
+			if !run.Timestamp.Before(cutoff) {
+				kept = append(kept, run)
+			}
+		}
+	}
+	if settings.KeepRuns > 0 && len(kept) > settings.KeepRuns {
+		kept = kept[len(kept)-settings.KeepRuns:]
+	}
+	if len(kept) == len(runs) {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(settings.File), filepath.Base(settings.File)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	w := bufio.NewWriter(tmp)
+	for _, run := range kept {
+		line, err := json.Marshal(run)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		w.Write(append(line, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), settings.File)
+}
+
+// loadHistory reads every run in the history file, oldest first. A line cut short by a crash is skipped.
+func loadHistory(filename string) ([]RunRecord, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []RunRecord
+	newerSchema := 0
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var run RunRecord
+		if err := json.Unmarshal([]byte(line), &run); err != nil {
+			fmt.Printf("⚠️  Warning: skipping unreadable history line %d: %v\n", i+1, err)
+			continue
+		}
+		if run.SchemaVersion > resultsSchemaVersion {
+			newerSchema++
+		}
+		runs = append(runs, run)
+	}
+	if newerSchema > 0 {
+		fmt.Printf("⚠️  Warning: %d history run(s) use results schema newer than %d; some fields may be missing\n",
+			newerSchema, resultsSchemaVersion)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Timestamp.Before(runs[j].Timestamp) })
+	return runs, nil
+}
+
+// Tables of the -db database: one runs row per benchmark run and one results row per test result
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp TEXT NOT NULL,
+		schema_version INTEGER NOT NULL,
+		arch TEXT NOT NULL,
+		total_ram_gb INTEGER NOT NULL,
+		virtualization TEXT NOT NULL,
+		fixed_tokens INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS results (
+		run_id INTEGER NOT NULL REFERENCES runs(id),
+		ollama_host TEXT NOT NULL,
+		model TEXT NOT NULL,
+		test_name TEXT NOT NULL,
+		category TEXT NOT NULL,
+		success INTEGER NOT NULL,
+		error TEXT NOT NULL,
+		tokens_per_second REAL NOT NULL,
+		time_to_first_token_ms REAL NOT NULL,
+		load_time_ms REAL NOT NULL,
+		total_tokens INTEGER NOT NULL,
+		prompt_tokens INTEGER NOT NULL,
+		prompt_tokens_per_second REAL NOT NULL,
+		total_time_ms REAL NOT NULL,
+		retries INTEGER NOT NULL,
+		repetitions INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS results_run_model ON results (run_id, ollama_host, model)`,
+}
+
+// openSQLite opens the -db database, creating it and its tables if needed
+func openSQLite(filename string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		return nil, err
+	}
+	for _, statement := range sqliteSchema {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// recordRunSQLite inserts a run and all of its test results in one transaction, so a failure leaves no partial run
+func recordRunSQLite(filename string, run RunRecord) error {
+	db, err := openSQLite(filename)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO runs (timestamp, schema_version, arch, total_ram_gb, virtualization, fixed_tokens)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		run.Timestamp.Format(time.RFC3339Nano), run.SchemaVersion, run.Arch, run.TotalRAMGB, run.Virtualization, run.FixedTokens)
+	if err != nil {
+		return err
+	}
+	runID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	insert, err := tx.Prepare(`INSERT INTO results (run_id, ollama_host, model, test_name, category, success, error,
+		tokens_per_second, time_to_first_token_ms, load_time_ms, total_tokens, prompt_tokens, prompt_tokens_per_second,
+		total_time_ms, retries, repetitions) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+	for _, s := range run.Summaries {
+		for _, r := range s.TestResults {
+			if _, err := insert.Exec(runID, s.OllamaHost, s.ModelName, r.TestName, r.Category, r.Success, r.Error,
+				r.TokensPerSecond, r.TimeToFirstToken, r.LoadTimeMs, r.TotalTokens, r.PromptTokens, r.PromptTokensPerSecond,
+				r.TotalTimeMs, r.Retries, r.Repetitions); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// loadRunsSQLite reads every run in the -db database, oldest first, with one summary per model whose tokens/sec is
+// the mean of its successful tests. Test results themselves stay in the database.
+func loadRunsSQLite(filename string) ([]RunRecord, error) {
+	if _, err := os.Stat(filename); err != nil {
+		return nil, err
+	}
+	db, err := openSQLite(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT id, timestamp, schema_version, arch, total_ram_gb, virtualization, fixed_tokens
+		FROM runs ORDER BY timestamp, id`)
+	if err != nil {
+		return nil, err
+	}
+	var runs []RunRecord
+	var ids []int64
+	for rows.Next() {
+		var run RunRecord
+		var id int64
+		var timestamp string
+		if err := rows.Scan(&id, &timestamp, &run.SchemaVersion, &run.Arch, &run.TotalRAMGB, &run.Virtualization, &run.FixedTokens); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if run.Timestamp, err = time.Parse(time.RFC3339Nano, timestamp); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("run %d: %v", id, err)
+		}
+		runs = append(runs, run)
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, id := range ids {
+		rows, err := db.Query(`SELECT ollama_host, model, AVG(tokens_per_second), COUNT(*) FROM results
+			WHERE run_id = ? AND success GROUP BY ollama_host, model ORDER BY ollama_host, model`, id)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			s := ModelSummary{CanRun: true}
+			if err := rows.Scan(&s.OllamaHost, &s.ModelName, &s.AvgTokensPerSec, &s.PassedTests); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			s.MeanTokensPerSec = s.AvgTokensPerSec
+			runs[i].Summaries = append(runs[i].Summaries, s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
+
+// parseSince accepts a date ("2024-06-03"), an RFC 3339 timestamp, or a relative age ("7d", "36h")
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use 2006-01-02, an RFC 3339 timestamp, 7d or 36h)", value)
+}
+
+// runCompareCommand compares the latest run in the history file, or the -db database, against an earlier one
+func runCompareCommand(args []string) {
+	compareFlags := flag.NewFlagSet("compare", flag.ExitOnError)
+	configFile := compareFlags.String("config", "config.json", "Config file whose history.file is used when -history is not given")
+	historyFile := compareFlags.String("history", "", "History file to read (default: history.file from the config)")
+	dbFile := compareFlags.String("db", "", "Read the runs from this SQLite database, as written by -db, instead of the history file")
+	since := compareFlags.String("history-since", "", "Compare against the first run at or after this time (2006-01-02, RFC 3339, 7d, 36h); default is the previous run")
+	compareFlags.Parse(args)
+
+	if *dbFile != "" {
+		*historyFile = *dbFile
+	} else if *historyFile == "" {
+		if config, err := loadConfig(*configFile); err == nil {
+			*historyFile = config.History.File
+		} else {
+			fmt.Printf("Error loading config: %v\n", err)
+			return
+		}
+	}
+	if *historyFile == "" {
+		fmt.Printf("Error: no history file. Set history.file in %s or pass -history FILE\n", *configFile)
+		return
+	}
+
+	load := loadHistory
+	if *dbFile != "" {
+		load = loadRunsSQLite
+	}
+	runs, err := load(*historyFile)
+	if err != nil {
+		fmt.Printf("Error reading history: %v\n", err)
+		return
+	}
+	if len(runs) < 2 {
+		fmt.Printf("Need at least two runs in %s to compare (found %d)\n", *historyFile, len(runs))
+		return
+	}
+
+	latest := runs[len(runs)-1]
+	baseline := runs[len(runs)-2]
+	if *since != "" {
+		sinceTime, err := parseSince(*since, time.Now())
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		found := false
+		for _, run := range runs[:len(runs)-1] {
+			if !run.Timestamp.Before(sinceTime) {
+				baseline = run
+				found = true
+				break
+			}
+		}
+		if !found {
+			fmt.Printf("No run before the latest one at or after %s\n", sinceTime.Format("2006-01-02 15:04"))
+			return
+		}
+	}
+
+	fmt.Printf("Baseline: %s | Latest: %s\n\n", baseline.Timestamp.Format("2006-01-02 15:04"), latest.Timestamp.Format("2006-01-02 15:04"))
+	if changes := serverSettingChanges(baseline, latest); len(changes) > 0 {
+		fmt.Println("Server settings changed between these runs, so the deltas below include their effect:")
+		for _, change := range changes {
+			fmt.Printf("  %s\n", change)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%-25s | %14s | %14s | %8s\n", "Model", "Baseline t/s", "Latest t/s", "Change")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	baselineTPS := make(map[string]float64)
+	for _, s := range baseline.Summaries {
+		if s.CanRun {
+			baselineTPS[s.OllamaHost+" "+s.ModelName] = s.AvgTokensPerSec
+		}
+	}
+	for _, s := range latest.Summaries {
+		if !s.CanRun {
+			continue
+		}
+		before, ok := baselineTPS[s.OllamaHost+" "+s.ModelName]
+		if !ok || before == 0 {
+			fmt.Printf("%-25s | %14s | %14s | %8s\n", s.ModelName, "n/a", formatMetric(s.AvgTokensPerSec), "new")
+			continue
+		}
+		change := (s.AvgTokensPerSec - before) / before * 100
+		fmt.Printf("%-25s | %14s | %14s | %+7.1f%%\n", s.ModelName, formatMetric(before), formatMetric(s.AvgTokensPerSec), change)
+	}
+}
+
+// serverSettingChanges lists the server-global settings that differ between two runs of the same endpoint
+func serverSettingChanges(baseline, latest RunRecord) []string {
+	if baseline.Metadata == nil || latest.Metadata == nil {
+		return nil
+	}
+	var changes []string
+	for host, after := range latest.Metadata.ServerSettings {
+		before, ok := baseline.Metadata.ServerSettings[host]
+		if !ok {
+			continue
+		}
+		for _, name := range serverSettingNames {
+			if before[name] != after[name] {
+				changes = append(changes, fmt.Sprintf("%s %s: %s → %s", hostLabel(host), name, before[name], after[name]))
+			}
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// Reporter renders a finished run in one output format; adding a format means adding one type to reporters
+type Reporter interface {
+	Extension() string
+	Write(w io.Writer, run RunRecord) error
+}
+
+var reporters = map[string]Reporter{
+	"json":     jsonReporter{},
+	"csv":      csvReporter{},
+	"markdown": markdownReporter{},
+	"html":     htmlReporter{},
+	"text":     textReporter{},
+	"plain":    plainReporter{},
+}
+
+// formatList collects repeatable -format flags
+type formatList []string
+
+func (f *formatList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *formatList) Set(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := reporters[name]; !ok {
+			return fmt.Errorf("unknown format %q (use json, csv, markdown, html, text or plain)", name)
+		}
+		*f = append(*f, name)
+	}
+	return nil
+}
+
+// reportPath appends the format's extension to the -out base unless it is already there
+func reportPath(out string, reporter Reporter) string {
+	ext := "." + reporter.Extension()
+	if strings.HasSuffix(out, ext) {
+		return out
+	}
+	return out + ext
+}
+
+// writeReport renders the run into filename, plus a metadata sidecar for CSV and a SHA-256 sidecar for JSON when sign is set
+func writeReport(filename string, reporter Reporter, run RunRecord, sign bool) error {
+	var buf bytes.Buffer
+	if err := reporter.Write(&buf, run); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	if _, isCSV := reporter.(csvReporter); isCSV {
+		meta := strings.Join(metadataLines(run), "\n") + "\n"
+		return os.WriteFile(filename+".meta.txt", []byte(meta), 0644)
+	}
+	if _, isJSON := reporter.(jsonReporter); !sign || !isJSON {
+		return nil
+	}
+
+	sum, err := canonicalJSONHash(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	// The hash covers the canonical form rather than the file bytes, so reformatting the JSON doesn't break -verify
+	return os.WriteFile(filename+".sha256", []byte(fmt.Sprintf("%s  %s\n", sum, filepath.Base(filename))), 0644)
+}
+
+// rankedSummaries returns the models that ran, fastest first, as in the console ranking
+func rankedSummaries(summaries []ModelSummary) []ModelSummary {
+	var ranked []ModelSummary
+	for _, s := range summaries {
+		if s.CanRun {
+			ranked = append(ranked, s)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].AvgTokensPerSec > ranked[j].AvgTokensPerSec
+	})
+	return ranked
+}
+
+// Full run, including every test result, as indented JSON
+type jsonReporter struct{}
+
+func (jsonReporter) Extension() string { return "json" }
+
+func (jsonReporter) Write(w io.Writer, run RunRecord) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// One row per test result, for spreadsheets
+type csvReporter struct {
+	Responses bool // append the (possibly truncated) response text as a last column
+}
+
+func (csvReporter) Extension() string { return "csv" }
+
+func (c csvReporter) Write(w io.Writer, run RunRecord) error {
+	// Provenance goes to a .meta.txt sidecar (see writeReport) so spreadsheets see a plain header row
+	cw := csv.NewWriter(w)
+	// The first eleven columns are the documented layout; later additions only ever go after them
+	header := []string{"model", "size", "test", "category", "tokens_per_sec", "ttft_ms", "total_tokens", "prompt_tokens",
+		"total_time_ms", "success", "error", "host", "retries", "repetitions", "tokens_per_sec_stddev"}
+	if c.Responses {
+		header = append(header, "response")
+	}
+	cw.Write(header)
+	for _, s := range run.Summaries {
+		for _, r := range s.TestResults {
+			// encoding/csv quotes commas, quotes and newlines in the error and response text
+			row := []string{s.ModelName, s.ModelSize, r.TestName, r.Category,
+				formatMetric(r.TokensPerSecond), formatMetric(r.TimeToFirstToken), strconv.Itoa(r.TotalTokens),
+				strconv.Itoa(r.PromptTokens), formatMetric(r.TotalTimeMs), strconv.FormatBool(r.Success), r.Error,
+				s.OllamaHost, strconv.Itoa(r.Retries), strconv.Itoa(r.Repetitions), formatMetric(r.TokensPerSecStdDev)}
+			if c.Responses {
+				row = append(row, r.Response)
+			}
+			cw.Write(row)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Ranking and per-test tables for pasting into issues and wikis
+type markdownReporter struct{}
+
+func (markdownReporter) Extension() string { return "md" }
+
+func (markdownReporter) Write(w io.Writer, run RunRecord) error {
+	fmt.Fprint(w, "# Benchmark Results\n\n")
+	fmt.Fprintf(w, "- Date: %s\n- Architecture: %s\n- RAM: %d GB\n", run.Timestamp.Format(time.RFC1123), run.Arch, run.TotalRAMGB)
+	if run.Virtualization != "" {
+		fmt.Fprintf(w, "- Virtualization: %s\n", run.Virtualization)
+	}
+	fmt.Fprint(w, "\n## Ranking\n\n")
+	fmt.Fprintln(w, "| # | Model | Host | Size | Passed | Mean t/s | Weighted t/s | Avg Time ms |")
+	fmt.Fprintln(w, "|---|-------|------|------|--------|----------|--------------|-------------|")
+	ranked := rankedSummaries(run.Summaries)
+	for i, s := range ranked {
+		fmt.Fprintf(w, "| %d | %s | %s | %s | %d/%d | %s | %s | %s |\n", i+1, s.ModelName, hostLabel(s.OllamaHost), s.ModelSize,
+			s.PassedTests, len(s.TestResults), formatMetric(s.MeanTokensPerSec), formatMetric(s.WeightedTokensPerSec), formatMetric(s.AvgTotalTimeMs))
+	}
+
+	best := bestPerCategory(ranked)
+	if len(best) > 0 {
+		fmt.Fprint(w, "\n## Best Model per Category\n\n")
+		for _, b := range best {
+			fmt.Fprintf(w, "- **%s**: %s on %s (%s t/s)\n", b.Category, b.Summary.ModelName, hostLabel(b.Summary.OllamaHost),
+				formatMetric(b.TokensPerSec))
+		}
+
+		fmt.Fprint(w, "\n## Categories\n")
+		for _, b := range best {
+			fmt.Fprintf(w, "\n### %s\n\n", b.Category)
+			fmt.Fprintln(w, "| Model | Host | Test | t/s | Total ms | Tokens |")
+			fmt.Fprintln(w, "|-------|------|------|-----|----------|--------|")
+			for _, s := range ranked {
+				for _, r := range s.TestResults {
+					if r.Category == b.Category && r.Success {
+						fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %d |\n", s.ModelName, hostLabel(s.OllamaHost), r.TestName,
+							formatMetric(r.TokensPerSecond), formatMetric(r.TotalTimeMs), r.TotalTokens)
+					}
+				}
+			}
+		}
+	}
+
+	fmt.Fprint(w, "\n## Run Metadata\n\n")
+	for _, line := range metadataLines(run) {
+		fmt.Fprintf(w, "- %s\n", line)
+	}
+
+	fmt.Fprint(w, "\n## Test Results\n\n")
+	fmt.Fprintln(w, "| Model | Test | Category | t/s | Total ms | Tokens | Result |")
+	fmt.Fprintln(w, "|-------|------|----------|-----|----------|--------|--------|")
+	for _, s := range run.Summaries {
+		for _, r := range s.TestResults {
+			status := "ok"
+			if !r.Success {
+				status = strings.ReplaceAll(r.Error, "|", "\\|")
+			}
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %d | %s |\n", s.ModelName, r.TestName, r.Category,
+				formatMetric(r.TokensPerSecond), formatMetric(r.TotalTimeMs), r.TotalTokens, status)
+		}
+	}
+	return nil
+}
+
+// categoryBest is the model with the fastest successful test in one category
+type categoryBest struct {
+	Category     string
+	Summary      ModelSummary
+	TokensPerSec float64
+}
+
+// bestPerCategory returns the fastest model of each category that has a successful test, sorted by category
+func bestPerCategory(summaries []ModelSummary) []categoryBest {
+	best := make(map[string]categoryBest)
+	for _, s := range summaries {
+		for _, r := range s.TestResults {
+			if r.Success && r.TokensPerSecond > best[r.Category].TokensPerSec {
+				best[r.Category] = categoryBest{Category: r.Category, Summary: s, TokensPerSec: r.TokensPerSecond}
+			}
+		}
+	}
+	var list []categoryBest
+	for _, b := range best {
+		list = append(list, b)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Category < list[j].Category })
+	return list
+}
+
+// Self-contained page with the same tables as the markdown report
+type htmlReporter struct{}
+
+func (htmlReporter) Extension() string { return "html" }
+
+func (htmlReporter) Write(w io.Writer, run RunRecord) error {
+	esc := html.EscapeString
+	fmt.Fprintln(w, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Benchmark Results</title>")
+	fmt.Fprintln(w, "<style>body{font-family:sans-serif}table{border-collapse:collapse}td,th{border:1px solid #ccc;padding:4px 8px}</style>\n</head>\n<body>")
+	fmt.Fprintf(w, "<h1>Benchmark Results</h1>\n<p>%s, %s, %d GB RAM</p>\n", esc(run.Timestamp.Format(time.RFC1123)), esc(run.Arch), run.TotalRAMGB)
+
+	fmt.Fprintln(w, "<h2>Ranking</h2>\n<table>")
+	fmt.Fprintln(w, "<tr><th>#</th><th>Model</th><th>Host</th><th>Size</th><th>Passed</th><th>Mean t/s</th><th>Weighted t/s</th><th>Avg Time ms</th></tr>")
+	for i, s := range rankedSummaries(run.Summaries) {
+		fmt.Fprintf(w, "<tr><td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%d/%d</td><td>%s</td><td>%s</td><td>%s</td></tr>\n", i+1,
+			esc(s.ModelName), esc(hostLabel(s.OllamaHost)), esc(s.ModelSize), s.PassedTests, len(s.TestResults),
+			formatMetric(s.MeanTokensPerSec), formatMetric(s.WeightedTokensPerSec), formatMetric(s.AvgTotalTimeMs))
+	}
+	fmt.Fprintln(w, "</table>")
+
+	fmt.Fprintln(w, "<h2>Run Metadata</h2>\n<ul>")
+	for _, line := range metadataLines(run) {
+		fmt.Fprintf(w, "<li>%s</li>\n", esc(line))
+	}
+	fmt.Fprintln(w, "</ul>")
+
+	fmt.Fprintln(w, "<h2>Test Results</h2>\n<table>")
+	fmt.Fprintln(w, "<tr><th>Model</th><th>Test</th><th>Category</th><th>t/s</th><th>Total ms</th><th>Tokens</th><th>Result</th></tr>")
+	for _, s := range run.Summaries {
+		for _, r := range s.TestResults {
+			status := "ok"
+			if !r.Success {
+				status = r.Error
+			}
+			fmt.Fprintf(w, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%s</td></tr>\n",
+				esc(s.ModelName), esc(r.TestName), esc(r.Category), formatMetric(r.TokensPerSecond),
+				formatMetric(r.TotalTimeMs), r.TotalTokens, esc(status))
+		}
+	}
+	_, err := fmt.Fprintln(w, "</table>\n</body>\n</html>")
+	return err
+}
+
+// Plain-text ranking, the same rows as the console report
+type textReporter struct{}
+
+func (textReporter) Extension() string { return "txt" }
+
+func (textReporter) Write(w io.Writer, run RunRecord) error {
+	fmt.Fprintf(w, "Benchmark Results (%s, %s, %d GB RAM)\n\n", run.Timestamp.Format(time.RFC1123), run.Arch, run.TotalRAMGB)
+	for i, s := range rankedSummaries(run.Summaries) {
+		fmt.Fprintf(w, "%d. %-25s | Host: %-20s | Size: %-8s | Passed: %d/%d | Mean: %6s t/s | Weighted: %6s t/s | Avg Time: %7s ms\n",
+			i+1, s.ModelName, hostLabel(s.OllamaHost), s.ModelSize, s.PassedTests, len(s.TestResults),
+			formatMetric(s.MeanTokensPerSec), formatMetric(s.WeightedTokensPerSec), formatMetric(s.AvgTotalTimeMs))
+	}
+	for _, s := range run.Summaries {
+		if !s.CanRun {
+			fmt.Fprintf(w, "-  %-25s | skipped: %s\n", s.ModelName, s.SkipReason)
+		}
+	}
+
+	fmt.Fprintln(w, "\nRun metadata:")
+	for _, line := range metadataLines(run) {
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+	return nil
+}
+
+// Stable layout for committing results to a repo: fixed-width columns, ASCII labels, and rows sorted by
+// host, model and test instead of by speed, so two runs diff line by line
+type plainReporter struct{}
+
+func (plainReporter) Extension() string { return "plain.txt" }
+
+// Metadata that changes on every run; the plain report keeps it out of the header, on one trailing line
+var volatileMetadataKeys = []string{"timestamp: ", "tool commit: ", "ollama: ", "go: ", "args: "}
+
+func (plainReporter) Write(w io.Writer, run RunRecord) error {
+	var volatile []string
+	for _, line := range metadataLines(run) {
+		isVolatile := false
+		for _, key := range volatileMetadataKeys {
+			if strings.HasPrefix(line, key) {
+				isVolatile = true
+				break
+			}
+		}
+		if isVolatile {
+			volatile = append(volatile, line)
+		} else {
+			fmt.Fprintf(w, "# %s\n", line)
+		}
+	}
+
+	summaries := append([]ModelSummary(nil), run.Summaries...)
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if summaries[i].OllamaHost != summaries[j].OllamaHost {
+			return summaries[i].OllamaHost < summaries[j].OllamaHost
+		}
+		return summaries[i].ModelName < summaries[j].ModelName
+	})
+
+	fmt.Fprintln(w, "\n[models]")
+	fmt.Fprintf(w, "%-30s %-22s %-8s %5s %12s %12s %12s\n", "model", "host", "size", "pass", "mean_tps", "weighted_tps", "avg_ms")
+	for _, s := range summaries {
+		if !s.CanRun {
+			continue
+		}
+		fmt.Fprintf(w, "%-30s %-22s %-8s %5s %12s %12s %12s\n",
+			fixedWidth(s.ModelName, 30), fixedWidth(hostLabel(s.OllamaHost), 22), fixedWidth(s.ModelSize, 8),
+			fmt.Sprintf("%d/%d", s.PassedTests, len(s.TestResults)),
+			formatMetric(s.MeanTokensPerSec), formatMetric(s.WeightedTokensPerSec), formatMetric(s.AvgTotalTimeMs))
+	}
+
+	fmt.Fprintln(w, "\n[tests]")
+	fmt.Fprintf(w, "%-30s %-22s %-24s %-6s %12s %8s %12s\n", "model", "host", "test", "status", "tps", "tokens", "total_ms")
+	for _, s := range summaries {
+		results := append([]BenchmarkResult(nil), s.TestResults...)
+		sort.SliceStable(results, func(i, j int) bool { return results[i].TestName < results[j].TestName })
+		for _, r := range results {
+			status := "ok"
+			if !r.Success {
+				status = "failed"
+			}
+			fmt.Fprintf(w, "%-30s %-22s %-24s %-6s %12s %8d %12s\n",
+				fixedWidth(s.ModelName, 30), fixedWidth(hostLabel(s.OllamaHost), 22), fixedWidth(r.TestName, 24), status,
+				formatMetric(r.TokensPerSecond), r.TotalTokens, formatMetric(r.TotalTimeMs))
+		}
+	}
+
+	fmt.Fprintln(w, "\n[skipped]")
+	fmt.Fprintf(w, "%-30s %-22s %s\n", "model", "host", "reason")
+	for _, s := range summaries {
+		if !s.CanRun {
+			fmt.Fprintf(w, "%-30s %-22s %s\n", fixedWidth(s.ModelName, 30), fixedWidth(hostLabel(s.OllamaHost), 22), s.SkipReason)
+		}
+	}
+
+	fmt.Fprintf(w, "\n# run: %s\n", strings.Join(volatile, "; "))
+	return nil
+}
+
+// fixedWidth cuts s to width characters, marking the cut with "~", so long names can't shift the columns
+func fixedWidth(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+	return s[:width-1] + "~"
+}
+
+// verifyJSONResults recomputes the canonical hash of an export and compares it to its .sha256 file
+func verifyJSONResults(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	sidecar, err := os.ReadFile(filename + ".sha256")
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(string(sidecar))
+	if len(fields) == 0 {
+		return fmt.Errorf("%s.sha256 is empty", filename)
+	}
+
+	sum, err := canonicalJSONHash(data)
+	if err != nil {
+		return err
+	}
+	if sum != strings.ToLower(fields[0]) {
+		return fmt.Errorf("hash mismatch: results were modified (expected %s, got %s)", fields[0], sum)
+	}
+	return nil
+}
+
+// canonicalJSONHash hashes JSON independent of formatting: decoding into generic values and
+// re-encoding sorts object keys and drops whitespace, so only the data itself is covered
+func canonicalJSONHash(data []byte) (string, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // keep numbers exactly as written
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("invalid JSON: %v", err)
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimateModelRAM(t *testing.T) {
+	tests := []struct {
+		model  string
+		q4GB   int64
+		quant  string
+		factor float64
+		ramGB  int64
+	}{
+		// The whole parameter token is matched, so "32b" is not read as "2b"
+		{"qwen2.5:32b", 20, "q4", 1.0, 20},
+		{"gemma2:2b", 2, "q4", 1.0, 2},
+		{"qwen2.5:32b-instruct-q5_K_M", 20, "q5", 1.2, 24},
+		{"qwen2.5:32b-instruct-q8_0", 20, "q8", 1.5, 30},
+		{"llama3.1:8b-instruct-fp16", 6, "fp16", 2.0, 12},
+		// Sizes missing from the table fall back to parseParameterCount at 0.6 GB per billion
+		{"gpt-oss:20b", 12, "q4", 1.0, 12},
+		{"qwen3:30b-a3b-q8_0", 18, "q8", 1.5, 27},
+		// No size at all gets the 5 GB default
+		{"mistral", 5, "q4", 1.0, 5},
+	}
+	for _, tt := range tests {
+		q4GB, quant, factor := estimateModelRAMDetail(tt.model)
+		if q4GB != tt.q4GB || quant != tt.quant || factor != tt.factor {
+			t.Errorf("estimateModelRAMDetail(%q) = %d, %q, %.2f; want %d, %q, %.2f",
+				tt.model, q4GB, quant, factor, tt.q4GB, tt.quant, tt.factor)
+		}
+		if ramGB := estimateModelRAM(tt.model); ramGB != tt.ramGB {
+			t.Errorf("estimateModelRAM(%q) = %d; want %d", tt.model, ramGB, tt.ramGB)
+		}
+	}
+}
+
+func TestParseMemorySize(t *testing.T) {
+	tests := []struct {
+		input string
+		gb    MemorySize
+		ok    bool
+	}{
+		{"4GB", 4, true},
+		{"1.5 GB", 1.5, true},
+		{"512MB", 0.5, true},
+		{"512 mib", 0.5, true},
+		{"2G", 2, true},
+		{"1TB", 1024, true},
+		{"  8  ", 8, true},
+		{"GB", 0, false},
+		{"four GB", 0, false},
+		{"4 KB", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		gb, err := parseMemorySize(tt.input)
+		if (err == nil) != tt.ok || gb != tt.gb {
+			t.Errorf("parseMemorySize(%q) = %v, %v; want %v, ok=%v", tt.input, gb, err, tt.gb, tt.ok)
+		}
+	}
+}
+
+func TestMemorySizeUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json string
+		gb   MemorySize
+		ok   bool
+	}{
+		// A bare number stays GB, as in configs written before units were accepted
+		{`4`, 4, true},
+		{`0.5`, 0.5, true},
+		{`"4GB"`, 4, true},
+		{`"256MB"`, 0.25, true},
+		{`"lots"`, 0, false},
+		{`true`, 0, false},
+		{`[4]`, 0, false},
+	}
+	for _, tt := range tests {
+		var gb MemorySize
+		err := gb.UnmarshalJSON([]byte(tt.json))
+		if (err == nil) != tt.ok || gb != tt.gb {
+			t.Errorf("UnmarshalJSON(%s) = %v, %v; want %v, ok=%v", tt.json, gb, err, tt.gb, tt.ok)
+		}
+	}
+}
+
+func TestCanonicalJSONHash(t *testing.T) {
+	base, err := canonicalJSONHash([]byte(`{"a": 1, "b": [1.50, "x"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		json string
+		same bool
+	}{
+		// Whitespace and key order are not part of the data
+		{`{"b":[1.50,"x"],"a":1}`, true},
+		{"{\n\t\"a\": 1,\n\t\"b\": [\n\t\t1.50,\n\t\t\"x\"\n\t]\n}\n", true},
+		// Numbers are compared as written, so even an equal value in another spelling counts as a change
+		{`{"a": 1, "b": [1.5, "x"]}`, false},
+		{`{"a": 2, "b": [1.50, "x"]}`, false},
+		{`{"a": 1, "b": ["x", 1.50]}`, false},
+	}
+	for _, tt := range tests {
+		sum, err := canonicalJSONHash([]byte(tt.json))
+		if err != nil {
+			t.Errorf("canonicalJSONHash(%q): %v", tt.json, err)
+			continue
+		}
+		if (sum == base) != tt.same {
+			t.Errorf("canonicalJSONHash(%q) matches the original: %v; want %v", tt.json, sum == base, tt.same)
+		}
+	}
+	if _, err := canonicalJSONHash([]byte(`{"a": `)); err == nil {
+		t.Error("canonicalJSONHash accepted truncated JSON")
+	}
+}
+
+func TestVerifyJSONResults(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "results.json")
+	run := RunRecord{SchemaVersion: resultsSchemaVersion, Arch: "arm64", TotalRAMGB: 64}
+	if err := writeReport(filename, jsonReporter{}, run, true); err != nil {
+		t.Fatal(err)
+	}
+	original, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyJSONResults(filename); err != nil {
+		t.Fatalf("fresh export: %v", err)
+	}
+
+	// Re-indenting, e.g. by an editor or jq, keeps the data and must still verify
+	var reindented bytes.Buffer
+	if err := json.Indent(&reindented, bytes.TrimSpace(original), "", "    "); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, reindented.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyJSONResults(filename); err != nil {
+		t.Errorf("re-indented export: %v", err)
+	}
+
+	edited := strings.Replace(string(original), `"total_ram_gb": 64`, `"total_ram_gb": 65`, 1)
+	if edited == string(original) {
+		t.Fatal("total_ram_gb not found in the export")
+	}
+	if err := os.WriteFile(filename, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyJSONResults(filename); err == nil || !strings.Contains(err.Error(), "hash mismatch") {
+		t.Errorf("edited export: err = %v; want a hash mismatch", err)
+	}
+}
+
+func TestRotateHistory(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	ages := []int{20, 15, 8, 3, 1} // days before now, oldest first as the file is appended
+	tests := []struct {
+		settings HistorySettings
+		kept     []int
+	}{
+		{HistorySettings{KeepRuns: 3}, []int{8, 3, 1}},
+		{HistorySettings{KeepDays: 10}, []int{8, 3, 1}},
+		// keep_days drops by age first, then keep_runs caps what is left
+		{HistorySettings{KeepDays: 10, KeepRuns: 2}, []int{3, 1}},
+		{HistorySettings{KeepDays: 16, KeepRuns: 10}, []int{15, 8, 3, 1}},
+		{HistorySettings{KeepDays: 30, KeepRuns: 2}, []int{3, 1}},
+		// Nothing to drop leaves the file as it was
+		{HistorySettings{KeepRuns: 10}, []int{20, 15, 8, 3, 1}},
+		{HistorySettings{}, []int{20, 15, 8, 3, 1}},
+	}
+	for _, tt := range tests {
+		tt.settings.File = filepath.Join(t.TempDir(), "history.jsonl")
+		var lines []string
+		for _, age := range ages {
+			line, err := json.Marshal(RunRecord{SchemaVersion: resultsSchemaVersion, Timestamp: now.AddDate(0, 0, -age)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines = append(lines, string(line))
+		}
+		// A run cut short by a crash mid-append
+		truncated := `{"schema_version":1,"arch":"arm`
+		lines = append(lines, truncated)
+		if err := os.WriteFile(tt.settings.File, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := rotateHistory(tt.settings, now); err != nil {
+			t.Errorf("%+v: %v", tt.settings, err)
+			continue
+		}
+		runs, err := loadHistory(tt.settings.File)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var kept []int
+		for _, run := range runs {
+			kept = append(kept, int(now.Sub(run.Timestamp).Hours()/24))
+		}
+		if len(kept) != len(tt.kept) {
+			t.Errorf("%+v: kept runs from %v days ago; want %v", tt.settings, kept, tt.kept)
+			continue
+		}
+		for i := range kept {
+			if kept[i] != tt.kept[i] {
+				t.Errorf("%+v: kept runs from %v days ago; want %v", tt.settings, kept, tt.kept)
+				break
+			}
+		}
+
+		// A rewrite only carries over the runs it could read, so the truncated line goes with it
+		data, err := os.ReadFile(tt.settings.File)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rewritten := len(tt.kept) < len(ages)
+		if present := strings.Contains(string(data), truncated); present == rewritten {
+			t.Errorf("%+v: truncated line present: %v; want %v", tt.settings, present, !rewritten)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"7d", now.AddDate(0, 0, -7), true},
+		{"0d", now, true},
+		{"36h", now.Add(-36 * time.Hour), true},
+		{"90m", now.Add(-90 * time.Minute), true},
+		{"2024-06-03", time.Date(2024, 6, 3, 0, 0, 0, 0, time.Local), true},
+		{"2024-06-03T08:30:00Z", time.Date(2024, 6, 3, 8, 30, 0, 0, time.UTC), true},
+		{"2024-06-03T08:30:00+02:00", time.Date(2024, 6, 3, 6, 30, 0, 0, time.UTC), true},
+		{"1.5d", time.Time{}, false},
+		{"7x", time.Time{}, false},
+		{"2024-13-01", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.value, now)
+		if (err == nil) != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v, ok=%v", tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/revoxz/LLMtesting
+
+go 1.16
+
+require modernc.org/sqlite v1.23.1
@@ -0,0 +1,273 @@
+// Package gguf reads the header metadata of GGUF model files and sizes them for loading.
+package gguf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// Info is the header metadata of a GGUF model file, enough to size and describe a custom model
+type Info struct {
+	Path           string
+	FileSizeBytes  int64
+	Architecture   string
+	ParameterCount int64
+	Quantization   string
+	ContextLength  int64
+}
+
+// "GGUF" read as a little-endian uint32
+const magic = 0x46554747
+
+// GGUF metadata value types
+const (
+	typeUint8 = iota
+	typeInt8
+	typeUint16
+	typeInt16
+	typeUint32
+	typeInt32
+	typeFloat32
+	typeBool
+	typeString
+	typeArray
+	typeUint64
+	typeInt64
+	typeFloat64
+)
+
+// llama.cpp general.file_type values
+var fileTypes = map[int64]string{
+	0: "F32", 1: "F16", 2: "Q4_0", 3: "Q4_1", 7: "Q8_0", 8: "Q5_0", 9: "Q5_1",
+	10: "Q2_K", 11: "Q3_K_S", 12: "Q3_K_M", 13: "Q3_K_L", 14: "Q4_K_S", 15: "Q4_K_M",
+	16: "Q5_K_S", 17: "Q5_K_M", 18: "Q6_K", 19: "IQ2_XXS", 20: "IQ2_XS", 21: "Q2_K_S",
+	22: "IQ3_XS", 23: "IQ3_XXS", 24: "IQ1_S", 25: "IQ4_NL", 26: "IQ3_S", 27: "IQ3_M",
+	28: "IQ2_S", 29: "IQ2_M", 30: "IQ4_XS", 31: "IQ1_M", 32: "BF16",
+}
+
+// ReadInfo parses the header, metadata and tensor index of a GGUF file; tensor data is never read
+func ReadInfo(path string) (*Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{Path: path, FileSizeBytes: stat.Size()}
+	r := bufio.NewReader(f)
+
+	var header struct {
+		Magic       uint32
+		Version     uint32
+		TensorCount uint64
+		KVCount     uint64
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("failed to read GGUF header: %v", err)
+	}
+	if header.Magic != magic {
+		return nil, fmt.Errorf("%s is not a GGUF file", path)
+	}
+	if header.Version < 2 {
+		return nil, fmt.Errorf("GGUF version %d is not supported (need 2 or later)", header.Version)
+	}
+
+	fileType := int64(-1)
+	for i := uint64(0); i < header.KVCount; i++ {
+		key, err := readString(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read metadata key: %v", err)
+		}
+		var valueType uint32
+		if err := binary.Read(r, binary.LittleEndian, &valueType); err != nil {
+			return nil, fmt.Errorf("failed to read type of %s: %v", key, err)
+		}
+		value, err := readValue(r, valueType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", key, err)
+		}
+
+		switch {
+		case key == "general.architecture":
+			info.Architecture, _ = value.(string)
+		case key == "general.parameter_count":
+			info.ParameterCount = metadataInt(value)
+		case key == "general.file_type":
+			fileType = metadataInt(value)
+		case strings.HasSuffix(key, ".context_length"):
+			info.ContextLength = metadataInt(value)
+		}
+	}
+
+	// Older converters don't write general.parameter_count; sum the tensor shapes instead
+	if info.ParameterCount == 0 {
+		for i := uint64(0); i < header.TensorCount; i++ {
+			if _, err := readString(r); err != nil {
+				return nil, fmt.Errorf("failed to read tensor name: %v", err)
+			}
+			var dims uint32
+			if err := binary.Read(r, binary.LittleEndian, &dims); err != nil {
+				return nil, err
+			}
+			elements := int64(1)
+			for d := uint32(0); d < dims; d++ {
+				var size uint64
+				if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+					return nil, err
+				}
+				elements *= int64(size)
+			}
+			var tensorType uint32
+			var offset uint64
+			if err := binary.Read(r, binary.LittleEndian, &tensorType); err != nil {
+				return nil, err
+			}
+			if err := binary.Read(r, binary.LittleEndian, &offset); err != nil {
+				return nil, err
+			}
+			info.ParameterCount += elements
+		}
+	}
+
+	info.Quantization = "unknown"
+	if name, ok := fileTypes[fileType]; ok {
+		info.Quantization = name
+	} else if fileType >= 0 {
+		info.Quantization = fmt.Sprintf("file type %d", fileType)
+	}
+
+	return info, nil
+}
+
+func readString(r *bufio.Reader) (string, error) {
+	var length uint64
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return "", err
+	}
+	// Guard against corrupt lengths; real keys and values are far below this
+	if length > 16*1024*1024 {
+		return "", fmt.Errorf("string length %d is implausible", length)
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// readValue decodes one metadata value; arrays (tokenizer vocabularies etc.) are skipped and returned as nil
+func readValue(r *bufio.Reader, valueType uint32) (interface{}, error) {
+	switch valueType {
+	case typeUint8:
+		var v uint8
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	case typeInt8:
+		var v int8
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	case typeUint16:
+		var v uint16
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	case typeInt16:
+		var v int16
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	case typeUint32:
+		var v uint32
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	case typeInt32:
+		var v int32
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	case typeFloat32:
+		var v float32
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	case typeBool:
+		var v uint8
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v != 0, err
+	case typeString:
+		return readString(r)
+	case typeArray:
+		var elemType uint32
+		var count uint64
+		if err := binary.Read(r, binary.LittleEndian, &elemType); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < count; i++ {
+			if _, err := readValue(r, elemType); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case typeUint64:
+		var v uint64
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	case typeInt64:
+		var v int64
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	case typeFloat64:
+		var v float64
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	}
+	return nil, fmt.Errorf("unknown value type %d", valueType)
+}
+
+// metadataInt widens any integer metadata value to int64
+func metadataInt(value interface{}) int64 {
+	switch v := value.(type) {
+	case uint8:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case int64:
+		return v
+	}
+	return 0
+}
+
+// FormatParameterCount renders 7241732096 as "7.2B" and 494032768 as "494M"
+func FormatParameterCount(count int64) string {
+	if count >= 1e9 {
+		return fmt.Sprintf("%.1fB", float64(count)/1e9)
+	}
+	return fmt.Sprintf("%dM", count/1e6)
+}
+
+// Loaded models need more than their file size for the KV cache and runtime buffers
+const RuntimeOverheadFactor = 1.2
+
+// RAMGB sizes a model from its file: weights are loaded as-is, plus runtime overhead
+func RAMGB(info *Info) int64 {
+	sizeGB := float64(info.FileSizeBytes) / (1024 * 1024 * 1024)
+	return int64(math.Ceil(sizeGB * RuntimeOverheadFactor))
+}
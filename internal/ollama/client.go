@@ -0,0 +1,228 @@
+// Package ollama is the Ollama HTTP client shared by the benchmark tools: endpoint parsing, timeouts and retries,
+// the health check, model listing, pulls and generate requests.
+package ollama
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultHost is used when neither a -host flag nor OLLAMA_HOST is given
+const DefaultHost = "http://localhost:11434"
+
+// Logf prints retry notices and other progress; a tool with a quiet mode replaces it
+var Logf = func(format string, a ...interface{}) {
+	fmt.Printf(format, a...)
+}
+
+// EnvHost returns OLLAMA_HOST, validated, or DefaultHost when it is unset
+func EnvHost() (string, error) {
+	value := os.Getenv("OLLAMA_HOST")
+	if strings.TrimSpace(value) == "" {
+		return DefaultHost, nil
+	}
+	host, err := ParseHost(value)
+	if err != nil {
+		return "", fmt.Errorf("OLLAMA_HOST: %v", err)
+	}
+	return host, nil
+}
+
+// ParseHost normalizes an endpoint and rejects anything that isn't an http(s) URL with a host
+func ParseHost(value string) (string, error) {
+	host := normalizeHost(value)
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid Ollama host %q: %v", value, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid Ollama host %q: scheme must be http or https", value)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("invalid Ollama host %q: missing host name", value)
+	}
+	return host, nil
+}
+
+// normalizeHost accepts "studio.local:11434" as well as full URLs
+func normalizeHost(host string) string {
+	host = strings.TrimRight(strings.TrimSpace(host), "/")
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return host
+}
+
+// Timeouts for Ollama calls: generate and embed requests may run as long as -timeout allows, while metadata
+// requests (/api/tags, /api/ps, /api/show, /api/version) should answer almost at once
+const (
+	DefaultRequestTimeout = 120 * time.Second
+	metadataTimeout       = 10 * time.Second
+)
+
+// Transient failures are retried up to httpRetries times, waiting retryBackoff and then doubling it
+const (
+	httpRetries  = 2
+	retryBackoff = time.Second
+)
+
+// Headers are added to every request sent to Ollama, e.g. the Authorization header an auth proxy in front of it
+// expects. Set them before the first request.
+var Headers = http.Header{}
+
+// headerTransport adds Headers to each request, replacing any header of the same name
+type headerTransport struct {
+	base http.RoundTripper
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(Headers) == 0 {
+		return t.base.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	for name, values := range Headers {
+		req.Header[name] = append([]string(nil), values...)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// NewClient returns a client for Ollama requests that gives up after timeout (0 = never) and sends Headers
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: headerTransport{http.DefaultTransport}}
+}
+
+// Shared clients, so a hung server fails one request instead of hanging the run. -timeout sets RequestClient's limit.
+var (
+	RequestClient  = NewClient(DefaultRequestTimeout)
+	MetadataClient = NewClient(metadataTimeout)
+	pullClient     = newPullClient()
+)
+
+// newPullClient bounds only the wait for the response headers: a pull streams progress for as long as the
+// download takes, which an overall timeout would cut short
+func newPullClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = metadataTimeout
+	return &http.Client{Transport: headerTransport{transport}}
+}
+
+// HeaderFlag is a repeatable -header flag: each "Name=Value" (or "Name: Value") is added to Headers
+type HeaderFlag struct{}
+
+func (HeaderFlag) String() string { return "" }
+
+func (HeaderFlag) Set(value string) error {
+	sep := strings.IndexAny(value, "=:")
+	if sep <= 0 {
+		return fmt.Errorf("header %q must look like Name=Value", value)
+	}
+	name := strings.TrimSpace(value[:sep])
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("invalid header name %q", name)
+	}
+	Headers.Add(name, strings.TrimSpace(value[sep+1:]))
+	return nil
+}
+
+// SetAuthToken sends token as a bearer token with every request; an empty token changes nothing
+func SetAuthToken(token string) {
+	if token != "" {
+		Headers.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// isTransientError reports failures worth retrying: the connection was refused, reset or closed early.
+// Timeouts aren't retried, since a server that was too slow once will usually be too slow again.
+func isTransientError(err error) bool {
+	if IsTimeout(err) {
+		return false
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// GenerateRetries is how often Generate retries transient failures. A tool that retries whole tests itself sets it
+// to 0, so every retry goes through its own loop and is counted there.
+var GenerateRetries = httpRetries
+
+// WithRetry calls send again after transient errors and 502/503/504 responses (Ollama answers 503 when its queue
+// is full), up to httpRetries times. The last response or error is returned as is.
+func WithRetry(send func() (*http.Response, error)) (*http.Response, error) {
+	return withRetries(httpRetries, send)
+}
+
+// withRetries is WithRetry with the number of retries given
+func withRetries(retries int, send func() (*http.Response, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := send()
+		var reason string
+		if err != nil && isTransientError(err) {
+			reason = err.Error()
+		} else if err == nil && (resp.StatusCode == http.StatusBadGateway ||
+			resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout) {
+			reason = fmt.Sprintf("status %d", resp.StatusCode)
+		}
+		if reason == "" || attempt >= retries {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		wait := retryBackoff << uint(attempt)
+		Logf("    ↻ %s; retrying in %v\n", reason, wait)
+		time.Sleep(wait)
+	}
+}
+
+// IsTimeout reports whether err is a client timeout
+func IsTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// How long the startup health check waits before calling the server unresponsive
+const HealthCheckTimeout = 5 * time.Second
+
+var (
+	ErrOllamaDown = errors.New("connection refused: Ollama is not running")
+	ErrOllamaHung = fmt.Errorf("timed out after %v: Ollama is running but overloaded or hung", HealthCheckTimeout)
+)
+
+// CheckHealth measures the /api/tags round trip and explains why the server is unreachable
+func CheckHealth(host string) (time.Duration, error) {
+	client := NewClient(HealthCheckTimeout)
+
+	// A server that is still starting refuses the first connections, so refusals are retried too
+	var startTime time.Time
+	resp, err := WithRetry(func() (*http.Response, error) {
+		startTime = time.Now()
+		return client.Get(host + "/api/tags")
+	})
+	if err != nil {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return 0, ErrOllamaDown
+		} else if IsTimeout(err) {
+			return 0, ErrOllamaHung
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	latency := time.Since(startTime)
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return latency, fmt.Errorf("status %d from /api/tags: the endpoint wants credentials; pass -auth-token or -header", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return latency, fmt.Errorf("unexpected status %d from /api/tags", resp.StatusCode)
+	}
+	return latency, nil
+}
@@ -0,0 +1,131 @@
+package ollama
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type GenerateRequest struct {
+	Model     string                 `json:"model"`
+	Prompt    string                 `json:"prompt"`
+	System    string                 `json:"system,omitempty"`
+	Stream    bool                   `json:"stream"`
+	KeepAlive string                 `json:"keep_alive,omitempty"` // "-1m" pins the model, "0" unloads it
+	Options   map[string]interface{} `json:"options,omitempty"`    // Ollama's options object, e.g. temperature, seed, num_ctx
+}
+
+type GenerateResponse struct {
+	Model              string    `json:"model"`
+	CreatedAt          time.Time `json:"created_at"`
+	Response           string    `json:"response"`
+	Done               bool      `json:"done"`
+	TotalDuration      int64     `json:"total_duration"`
+	LoadDuration       int64     `json:"load_duration"`
+	PromptEvalCount    int       `json:"prompt_eval_count"`
+	PromptEvalDuration int64     `json:"prompt_eval_duration"`
+	EvalCount          int       `json:"eval_count"`
+	EvalDuration       int64     `json:"eval_duration"`
+	Error              string    `json:"error,omitempty"`
+}
+
+// Generate sends req to /api/generate, retrying transient failures up to GenerateRetries times, and returns the response together with the
+// time the attempt that got through was sent. A streamed response is folded into one by DecodeStream, with onChunk
+// (which may be nil) seeing each chunk as it arrives; if the stream breaks off, what arrived is returned with the error.
+func Generate(host string, req GenerateRequest, onChunk func(chunk GenerateResponse, received time.Time)) (GenerateResponse, time.Time, error) {
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return GenerateResponse{}, time.Time{}, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	// Timing starts with the attempt that got through, not with any retried ones
+	var startTime time.Time
+	resp, err := withRetries(GenerateRetries, func() (*http.Response, error) {
+		startTime = time.Now()
+		return RequestClient.Post(host+"/api/generate", "application/json", bytes.NewReader(jsonData))
+	})
+	if err != nil {
+		if IsTimeout(err) {
+			return GenerateResponse{}, startTime, fmt.Errorf("no response within %v; raise -timeout for slow models", RequestClient.Timeout)
+		}
+		return GenerateResponse{}, startTime, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if req.Stream {
+		if onChunk == nil {
+			onChunk = func(GenerateResponse, time.Time) {}
+		}
+		genResp, err := DecodeStream(resp.Body, onChunk)
+		return genResp, startTime, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return GenerateResponse{}, startTime, fmt.Errorf("failed to read response: %v", err)
+	}
+	var genResp GenerateResponse
+	if err := json.Unmarshal(body, &genResp); err != nil {
+		return GenerateResponse{}, startTime, fmt.Errorf("failed to parse response: %v", err)
+	}
+	if genResp.Error != "" {
+		return GenerateResponse{}, startTime, errors.New(genResp.Error)
+	}
+	return genResp, startTime, nil
+}
+
+var ErrStreamIncomplete = errors.New("stream ended before the final chunk")
+
+// DecodeStream reads Ollama's newline-delimited JSON chunks, calling onChunk for each, and folds them into one
+// response: text is concatenated and counts/durations summed (Ollama reports them on the final chunk). If the stream
+// breaks off, the partial response is returned together with the error.
+func DecodeStream(r io.Reader, onChunk func(chunk GenerateResponse, received time.Time)) (GenerateResponse, error) {
+	var final GenerateResponse
+	var text strings.Builder
+	chunks := 0
+
+	decoder := json.NewDecoder(r)
+	for {
+		var chunk GenerateResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			final.Response = text.String()
+			if final.EvalCount == 0 {
+				// Without the final chunk, each content chunk is one token
+				final.EvalCount = chunks
+			}
+			if err == io.EOF {
+				return final, ErrStreamIncomplete
+			}
+			return final, fmt.Errorf("%w: %v", ErrStreamIncomplete, err)
+		}
+		if chunk.Error != "" {
+			final.Response = text.String()
+			return final, errors.New(chunk.Error)
+		}
+
+		onChunk(chunk, time.Now())
+		if chunk.Response != "" {
+			chunks++
+		}
+		text.WriteString(chunk.Response)
+		final.Model = chunk.Model
+		final.CreatedAt = chunk.CreatedAt
+		final.TotalDuration += chunk.TotalDuration
+		final.LoadDuration += chunk.LoadDuration
+		final.PromptEvalCount += chunk.PromptEvalCount
+		final.PromptEvalDuration += chunk.PromptEvalDuration
+		final.EvalCount += chunk.EvalCount
+		final.EvalDuration += chunk.EvalDuration
+
+		if chunk.Done {
+			final.Done = true
+			final.Response = text.String()
+			return final, nil
+		}
+	}
+}
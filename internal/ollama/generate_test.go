@@ -0,0 +1,79 @@
+package ollama
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeStream(t *testing.T) {
+	tests := []struct {
+		name       string
+		stream     string
+		response   string
+		evalCount  int
+		done       bool
+		incomplete bool
+		errText    string
+		chunks     int
+	}{
+		{
+			name: "complete",
+			stream: `{"model":"m","response":"Hel","done":false}
+{"model":"m","response":"lo","done":false}
+{"model":"m","response":"","done":true,"eval_count":7,"prompt_eval_count":3}
+`,
+			response: "Hello", evalCount: 7, done: true, chunks: 3,
+		},
+		{
+			// No final chunk, so the eval count falls back to the content chunks seen; the empty one isn't counted
+			name: "ends before done",
+			stream: `{"model":"m","response":"a","done":false}
+{"model":"m","response":"","done":false}
+{"model":"m","response":"b","done":false}
+`,
+			response: "ab", evalCount: 2, incomplete: true, chunks: 3,
+		},
+		{
+			name: "cut mid-line",
+			stream: `{"model":"m","response":"a","done":false}
+{"model":"m","respo`,
+			response: "a", evalCount: 1, incomplete: true, chunks: 1,
+		},
+		{
+			name:     "empty body",
+			stream:   "",
+			response: "", evalCount: 0, incomplete: true, chunks: 0,
+		},
+		{
+			name: "error chunk",
+			stream: `{"model":"m","response":"a","done":false}
+{"error":"model runner has unexpectedly stopped"}
+`,
+			response: "a", errText: "model runner has unexpectedly stopped", chunks: 1,
+		},
+	}
+	for _, tt := range tests {
+		chunks := 0
+		got, err := DecodeStream(strings.NewReader(tt.stream), func(GenerateResponse, time.Time) { chunks++ })
+		if got.Response != tt.response || got.EvalCount != tt.evalCount || got.Done != tt.done || chunks != tt.chunks {
+			t.Errorf("%s: got response %q, eval_count %d, done %v, %d chunks; want %q, %d, %v, %d",
+				tt.name, got.Response, got.EvalCount, got.Done, chunks, tt.response, tt.evalCount, tt.done, tt.chunks)
+		}
+		switch {
+		case tt.incomplete:
+			if !errors.Is(err, ErrStreamIncomplete) {
+				t.Errorf("%s: err = %v; want ErrStreamIncomplete", tt.name, err)
+			}
+		case tt.errText != "":
+			if err == nil || err.Error() != tt.errText {
+				t.Errorf("%s: err = %v; want %q", tt.name, err, tt.errText)
+			}
+		default:
+			if err != nil {
+				t.Errorf("%s: err = %v; want nil", tt.name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package ollama
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Model is one installed model as listed by /api/tags
+type Model struct {
+	Name       string    `json:"name"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Size       int64     `json:"size"`
+	Digest     string    `json:"digest"`
+	Details    struct {
+		ParameterSize string `json:"parameter_size"` // e.g. "8.0B" or "494.03M"
+	} `json:"details"`
+}
+
+type tagsResponse struct {
+	Models []Model `json:"models"`
+}
+
+// ListModels returns the models installed on host
+func ListModels(host string) ([]Model, error) {
+	resp, err := WithRetry(func() (*http.Response, error) {
+		return MetadataClient.Get(host + "/api/tags")
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tags tagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, err
+	}
+	return tags.Models, nil
+}
+
+// WithDefaultTag adds ":latest" to a name without a tag, the way Ollama resolves "llama3" to "llama3:latest".
+// A colon before the last "/" belongs to a registry port, not a tag.
+func WithDefaultTag(name string) string {
+	if strings.Contains(name[strings.LastIndex(name, "/")+1:], ":") {
+		return name
+	}
+	return name + ":latest"
+}
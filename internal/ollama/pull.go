@@ -0,0 +1,151 @@
+package ollama
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Pull failures worth telling apart: a wrong name won't fix itself, a network problem may on the next run
+var (
+	ErrModelNotFound = errors.New("model not found in the Ollama registry")
+	ErrPullNetwork   = errors.New("network failure during pull")
+)
+
+// One object of the /api/pull stream; completed and total count the bytes of the layer being downloaded
+type pullStatus struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest"`
+	Total     int64  `json:"total"`
+	Completed int64  `json:"completed"`
+	Error     string `json:"error"`
+}
+
+// classifyPullError wraps an error reported by Ollama in ErrModelNotFound or ErrPullNetwork when it is one of those
+func classifyPullError(message string) error {
+	lower := strings.ToLower(message)
+	for _, marker := range []string{"file does not exist", "manifest unknown", "not found"} {
+		if strings.Contains(lower, marker) {
+			return fmt.Errorf("%w: %s", ErrModelNotFound, message)
+		}
+	}
+	for _, marker := range []string{"dial tcp", "no such host", "connection reset", "connection refused", "timeout", "tls", "eof"} {
+		if strings.Contains(lower, marker) {
+			return fmt.Errorf("%w: %s", ErrPullNetwork, message)
+		}
+	}
+	return errors.New(message)
+}
+
+// PullFailureHint says what to do about a failed pull, empty when there is nothing specific to suggest
+func PullFailureHint(err error) string {
+	switch {
+	case errors.Is(err, ErrModelNotFound):
+		return "check the model name and tag at https://ollama.com/library"
+	case errors.Is(err, ErrPullNetwork):
+		return "check the network connection; Ollama resumes the download on the next pull"
+	}
+	return ""
+}
+
+// Width of the pull progress bar in characters
+const pullBarWidth = 30
+
+// pullProgress redraws one stderr line per layer as its download advances
+type pullProgress struct {
+	digest string
+	line   string
+}
+
+func (p *pullProgress) update(status pullStatus) {
+	if status.Total <= 0 {
+		return
+	}
+	if p.line != "" && status.Digest != p.digest {
+		fmt.Fprintln(os.Stderr)
+	}
+	p.digest = status.Digest
+
+	completed := status.Completed
+	if completed > status.Total {
+		completed = status.Total
+	}
+	filled := int(completed * pullBarWidth / status.Total)
+	line := fmt.Sprintf("  %s [%s%s] %3d%% (%s / %s)", status.Status,
+		strings.Repeat("#", filled), strings.Repeat("-", pullBarWidth-filled),
+		completed*100/status.Total, formatBytes(completed), formatBytes(status.Total))
+	if line != p.line {
+		fmt.Fprintf(os.Stderr, "\r%s", line)
+		p.line = line
+	}
+}
+
+// finish ends the last progress line so later output starts on a fresh one
+func (p *pullProgress) finish() {
+	if p.line != "" {
+		fmt.Fprintln(os.Stderr)
+		p.line = ""
+	}
+}
+
+// formatBytes renders a byte count in KB, MB or GB, whichever keeps it readable
+func formatBytes(n int64) string {
+	if n < 1024*1024 {
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	}
+	if n < 1024*1024*1024 {
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+	return fmt.Sprintf("%.2f GB", float64(n)/(1024*1024*1024))
+}
+
+// Pull downloads model, drawing a progress bar on stderr when showProgress is set. Errors wrap ErrModelNotFound
+// or ErrPullNetwork when the cause is known.
+func Pull(host string, model string, showProgress bool) error {
+	reqBody := map[string]string{"name": model}
+	jsonData, _ := json.Marshal(reqBody)
+
+	resp, err := WithRetry(func() (*http.Response, error) {
+		return pullClient.Post(host+"/api/pull", "application/json", bytes.NewReader(jsonData))
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPullNetwork, err)
+	}
+	defer resp.Body.Close()
+
+	var progress pullProgress
+	defer progress.finish()
+
+	// Read pull progress; failures arrive as {"error": "..."} either as the whole body or mid-stream
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var status pullStatus
+		if err := decoder.Decode(&status); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("%w: reading pull progress: %v", ErrPullNetwork, err)
+		}
+
+		if status.Error != "" {
+			return classifyPullError(status.Error)
+		}
+		if showProgress {
+			progress.update(status)
+		}
+		if strings.Contains(status.Status, "success") {
+			return nil
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: status 404 from /api/pull", ErrModelNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from /api/pull", resp.StatusCode)
+	}
+	return fmt.Errorf("%w: pull ended without a success status", ErrPullNetwork)
+}
@@ -0,0 +1,128 @@
+// Package sysinfo detects host facts both tools size models against: total RAM and the share held back for the OS.
+package sysinfo
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// DetectTotalRAM tries sysctl (macOS), then /proc/meminfo (Linux) or wmic (Windows), and caps the result
+// at the cgroup memory limit so containers report what they can actually use. Returns bytes and the source.
+func DetectTotalRAM() (int64, string, error) {
+	var ramBytes int64
+	source := ""
+
+	if runtime.GOOS == "windows" {
+		if b, src, ok := readWindowsTotalRAM(); ok {
+			ramBytes, source = b, src
+		}
+	} else if output, err := exec.Command("sysctl", "-n", "hw.memsize").Output(); err == nil {
+		if b, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err == nil && b > 0 {
+			ramBytes, source = b, "sysctl hw.memsize"
+		}
+	}
+	if ramBytes == 0 {
+		if b, ok := readMemInfoTotal(); ok {
+			ramBytes, source = b, "/proc/meminfo"
+		}
+	}
+	if limit, path, ok := readCgroupMemoryLimit(); ok && (ramBytes == 0 || limit < ramBytes) {
+		ramBytes, source = limit, path
+	}
+
+	if ramBytes == 0 {
+		return 0, "", errors.New("could not detect total RAM (sysctl, /proc/meminfo, wmic and cgroup limits unavailable); pass -total-ram-gb N")
+	}
+	return ramBytes, source, nil
+}
+
+// readWindowsTotalRAM reads TotalVisibleMemorySize (in KB) with wmic, or with PowerShell's CIM cmdlets on
+// Windows versions that no longer ship wmic
+func readWindowsTotalRAM() (int64, string, bool) {
+	if output, err := exec.Command("wmic", "OS", "get", "TotalVisibleMemorySize", "/value").Output(); err == nil {
+		for _, record := range ParseWindowsRecords(string(output)) {
+			if kb, err := strconv.ParseInt(record["TotalVisibleMemorySize"], 10, 64); err == nil && kb > 0 {
+				return kb * 1024, "wmic TotalVisibleMemorySize", true
+			}
+		}
+	}
+	ps := "(Get-CimInstance Win32_OperatingSystem).TotalVisibleMemorySize"
+	if output, err := exec.Command("powershell", "-NoProfile", "-Command", ps).Output(); err == nil {
+		if kb, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err == nil && kb > 0 {
+			return kb * 1024, "Win32_OperatingSystem TotalVisibleMemorySize", true
+		}
+	}
+	return 0, "", false
+}
+
+// ParseWindowsRecords splits "Key=Value" (wmic /value) or "Key : Value" (PowerShell Format-List) output
+// into one map per blank-line separated record. NULs and carriage returns from the console encoding are dropped.
+func ParseWindowsRecords(output string) []map[string]string {
+	output = strings.NewReplacer("\x00", "", "\r", "").Replace(output)
+	var records []map[string]string
+	record := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if len(record) > 0 {
+				records = append(records, record)
+				record = map[string]string{}
+			}
+			continue
+		}
+		sep := strings.Index(line, "=")
+		if colon := strings.Index(line, " : "); colon >= 0 && (sep < 0 || colon < sep) {
+			sep = colon
+		}
+		if sep < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:sep])
+		value := strings.TrimSpace(strings.TrimLeft(line[sep:], "=: "))
+		record[key] = value
+	}
+	if len(record) > 0 {
+		records = append(records, record)
+	}
+	return records
+}
+
+// readMemInfoTotal parses the MemTotal line of /proc/meminfo
+func readMemInfoTotal() (int64, bool) {
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			if kb, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+				return kb * 1024, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// readCgroupMemoryLimit returns the container memory limit from cgroup v2 or v1, if one is set
+func readCgroupMemoryLimit() (int64, string, bool) {
+	for _, path := range []string{"/sys/fs/cgroup/memory.max", "/sys/fs/cgroup/memory/memory.limit_in_bytes"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "max" {
+			return 0, "", false
+		}
+		// cgroup v1 reports "unlimited" as a huge page-aligned number; the caller keeps the smaller value
+		if limit, err := strconv.ParseInt(value, 10, 64); err == nil && limit > 0 {
+			return limit, path, true
+		}
+	}
+	return 0, "", false
+}
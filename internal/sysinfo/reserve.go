@@ -0,0 +1,48 @@
+package sysinfo
+
+import (
+	"math"
+	"sort"
+)
+
+// ReserveTier holds back RAM for the OS and apps on machines with up to UpToGB of RAM (0 = no upper bound)
+type ReserveTier struct {
+	UpToGB         int64   `json:"up_to_gb"`
+	ReservePercent float64 `json:"reserve_percent"`
+}
+
+// DefaultReserveTiers: small machines reserve a larger fraction, big machines a larger absolute amount:
+// 16 GB keeps ~6 GB back (35%), 32 GB ~10 GB (30%), 64 GB 16 GB (25%), 128 GB ~26 GB (20%)
+var DefaultReserveTiers = []ReserveTier{
+	{UpToGB: 16, ReservePercent: 35},
+	{UpToGB: 32, ReservePercent: 30},
+	{UpToGB: 64, ReservePercent: 25},
+	{UpToGB: 0, ReservePercent: 20},
+}
+
+// OSReserveGB picks the first tier (sorted by size) covering totalGB and returns the RAM it reserves.
+// No tiers means DefaultReserveTiers.
+func OSReserveGB(totalGB int64, tiers []ReserveTier) (int64, ReserveTier) {
+	if len(tiers) == 0 {
+		tiers = DefaultReserveTiers
+	}
+
+	sorted := append([]ReserveTier{}, tiers...)
+	sort.Slice(sorted, func(i, j int) bool {
+		// Unbounded tier (0) goes last
+		if sorted[i].UpToGB == 0 || sorted[j].UpToGB == 0 {
+			return sorted[j].UpToGB == 0 && sorted[i].UpToGB != 0
+		}
+		return sorted[i].UpToGB < sorted[j].UpToGB
+	})
+
+	tier := sorted[len(sorted)-1]
+	for _, t := range sorted {
+		if t.UpToGB == 0 || totalGB <= t.UpToGB {
+			tier = t
+			break
+		}
+	}
+
+	return int64(math.Ceil(float64(totalGB) * tier.ReservePercent / 100)), tier
+}